@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupPolicySpec lists resources that cleanup must never delete, whatever a CronJobScaleDown says.
+// A resource matching any of the criteria is protected.
+type CleanupPolicySpec struct {
+	// ProtectedNamespaces are never deleted and no resource in them is cleaned up
+	// +kubebuilder:validation:Optional
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+
+	// ProtectedKinds are never cleaned up. Entries are a kind (any group), "kind.group"
+	// or "group/version/kind" (e.g., "ClusterRole", "ClusterRoleBinding.rbac.authorization.k8s.io").
+	// +kubebuilder:validation:Optional
+	ProtectedKinds []string `json:"protectedKinds,omitempty"`
+
+	// ProtectedNamePatterns are shell glob patterns; resources whose name matches one are never cleaned up
+	// (e.g., "system:*", "*-prod")
+	// +kubebuilder:validation:Optional
+	ProtectedNamePatterns []string `json:"protectedNamePatterns,omitempty"`
+
+	// ProtectedLabels protects resources carrying any of these labels with the given value
+	// +kubebuilder:validation:Optional
+	ProtectedLabels map[string]string `json:"protectedLabels,omitempty"`
+
+	// ProtectedAnnotations protects resources carrying any of these annotations with the given value
+	// +kubebuilder:validation:Optional
+	ProtectedAnnotations map[string]string `json:"protectedAnnotations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// CleanupPolicy is the Schema for the cleanuppolicies API. It is cluster-scoped and meant
+// to be owned by cluster administrators; all CleanupPolicies apply to every cleanup run.
+type CleanupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CleanupPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CleanupPolicyList contains a list of CleanupPolicy.
+type CleanupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CleanupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CleanupPolicy{}, &CleanupPolicyList{})
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package v1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // CronJobScaleDownSpec defines the desired state of CronJobScaleDown.
@@ -42,10 +45,184 @@ type CronJobScaleDownSpec struct {
 	// +kubebuilder:validation:Optional
 	CleanupConfig *CleanupConfig `json:"cleanupConfig,omitempty"`
 
+	// CleanupPrecondition restricts cleanup runs to when the scaling target is down
+	// +kubebuilder:validation:Optional
+	CleanupPrecondition *CleanupPrecondition `json:"cleanupPrecondition,omitempty"`
+
 	// Timezone (e.g., "America/New_York", "UTC")
 	// +kubebuilder:validation:Required
 	// +kubebuilder:default:="UTC"
 	TimeZone string `json:"timeZone"`
+
+	// Priority orders this resource among others waiting for a scale-up slot when the
+	// operator limits concurrent scale-ups. Higher values are served first.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=0
+	Priority int32 `json:"priority,omitempty"`
+
+	// Hooks to run before and after scaling the target resource
+	// +kubebuilder:validation:Optional
+	Hooks *ScaleHooks `json:"hooks,omitempty"`
+
+	// DrainCondition defers scale down until the target's work has finished
+	// +kubebuilder:validation:Optional
+	DrainCondition *DrainCondition `json:"drainCondition,omitempty"`
+
+	// ScaleDownMode selects what scale down changes: Replicas scales the target to zero,
+	// Rightsize applies the RightsizeProfile to its containers' resources instead
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Replicas;Rightsize
+	// +kubebuilder:default:="Replicas"
+	ScaleDownMode string `json:"scaleDownMode,omitempty"`
+
+	// RightsizeProfile holds the container resources applied at scale down in Rightsize mode
+	// +kubebuilder:validation:Optional
+	RightsizeProfile *RightsizeProfile `json:"rightsizeProfile,omitempty"`
+}
+
+const (
+	// OrphanDetectionAge treats every unannotated resource older than the max age as an orphan
+	OrphanDetectionAge = "Age"
+	// OrphanDetectionOwnerReferences treats resources whose owners no longer exist as orphans
+	OrphanDetectionOwnerReferences = "OwnerReferences"
+	// OrphanDetectionUnreferenced treats ConfigMaps and Secrets nothing references as orphans
+	OrphanDetectionUnreferenced = "Unreferenced"
+)
+
+const (
+	// AgeSourceCreation measures the age of resources from their creation
+	AgeSourceCreation = "creation"
+	// AgeSourceLastUpdate measures the age of resources from their latest managedFields timestamp
+	AgeSourceLastUpdate = "lastUpdate"
+	// AgeSourceLastRollout measures the age of resources from the latest time of their status conditions,
+	// e.g. the last Deployment rollout
+	AgeSourceLastRollout = "lastRollout"
+	// AgeSourceLastPodStart measures the age of resources from the latest start of their pods
+	AgeSourceLastPodStart = "lastPodStart"
+)
+
+const (
+	// DateDeadlineStartOfDay expires date-only cleanup annotations at the midnight starting the date
+	DateDeadlineStartOfDay = "StartOfDay"
+	// DateDeadlineEndOfDay expires date-only cleanup annotations at the midnight ending the date
+	DateDeadlineEndOfDay = "EndOfDay"
+)
+
+const (
+	// PodPhaseEvicted matches failed pods evicted by the kubelet in CleanupConfig.PodPhases
+	PodPhaseEvicted = "Evicted"
+)
+
+const (
+	// ConditionTypeCleanupBlocked is True while cleanup runs are blocked by the deletion limits
+	ConditionTypeCleanupBlocked = "CleanupBlocked"
+	// AnnotationCleanupAcknowledged on a CronJobScaleDown lets the next cleanup run proceed past the
+	// deletion limits when its value is the number of planned deletions reported in the CleanupBlocked
+	// condition; it is removed once the run has been executed
+	AnnotationCleanupAcknowledged = "cronjob-scale-down-operator/cleanup-acknowledged"
+	// AnnotationRestoreArchive on a CronJobScaleDown recreates the objects of the named cleanup
+	// archive; it is removed once the restore has been attempted
+	AnnotationRestoreArchive = "cronjob-scale-down-operator/restore-archive"
+)
+
+const (
+	// ArchiveSinkSecret stores archives as compressed Secrets
+	ArchiveSinkSecret = "Secret"
+	// ArchiveSinkConfigMap stores archives as compressed ConfigMaps
+	ArchiveSinkConfigMap = "ConfigMap"
+	// ArchiveSinkDirectory stores archives as compressed files in a local directory, e.g. a mounted PVC
+	ArchiveSinkDirectory = "Directory"
+)
+
+// Cleanup report actions
+const (
+	CleanupActionDeleted         = "Deleted"
+	CleanupActionDeleting        = "Deleting"
+	CleanupActionWouldDelete     = "WouldDelete"
+	CleanupActionQuarantined     = "Quarantined"
+	CleanupActionWouldQuarantine = "WouldQuarantine"
+	CleanupActionFailed          = "Failed"
+	CleanupActionSkipped         = "Skipped"
+)
+
+// Cleanup report reasons for the resources a run deletes
+const (
+	CleanupReasonAnnotation   = "annotation"
+	CleanupReasonImmediate    = "immediate"
+	CleanupReasonOrphanAge    = "orphan age"
+	CleanupReasonUnreferenced = "unreferenced"
+	CleanupReasonIdle         = "idle namespace"
+	CleanupReasonCascade      = "cascade"
+)
+
+const (
+	// ScaleDownModeReplicas scales the target to zero replicas
+	ScaleDownModeReplicas = "Replicas"
+	// ScaleDownModeRightsize patches the target's container resources
+	ScaleDownModeRightsize = "Rightsize"
+)
+
+// RightsizeProfile describes the container resources used while the target is scaled down
+type RightsizeProfile struct {
+	// Resources per container of the target's pod template
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Containers []ContainerResources `json:"containers"`
+}
+
+// ContainerResources sets the resources of a container
+type ContainerResources struct {
+	// Name of the container; "*" applies to every container not listed by name
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Resource requests to apply; resources not listed keep their value
+	// +kubebuilder:validation:Optional
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+
+	// Resource limits to apply; resources not listed keep their value
+	// +kubebuilder:validation:Optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// DrainCondition describes when the target is idle enough to be scaled down.
+// All configured checks must hold.
+type DrainCondition struct {
+	// Labels of Jobs, in the target's namespace, that must all have finished
+	// +kubebuilder:validation:Optional
+	JobSelector map[string]string `json:"jobSelector,omitempty"`
+
+	// Annotation that every running pod of the target must set to "true" to report itself idle
+	// +kubebuilder:validation:Optional
+	PodIdleAnnotation string `json:"podIdleAnnotation,omitempty"`
+
+	// Pod condition type (e.g. a readiness gate) that must be True on every running pod of the target
+	// +kubebuilder:validation:Optional
+	PodIdleCondition string `json:"podIdleCondition,omitempty"`
+
+	// Longest time to defer scale down; once it passes the target is scaled down anyway (e.g., "1h")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="1h"
+	MaxDelay string `json:"maxDelay,omitempty"`
+
+	// How often the condition is checked while scale down is deferred (e.g., "1m")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="1m"
+	CheckInterval string `json:"checkInterval,omitempty"`
+}
+
+// CleanupPrecondition describes the state of the scaling target a cleanup run requires. A run finding the
+// target in another state is skipped and recorded with the reason in the cleanup history.
+// All configured checks must hold.
+type CleanupPrecondition struct {
+	// RequireTargetDown runs cleanup only while the target is in its scale-down window, i.e. it was
+	// scaled down more recently than up, and has 0 replicas
+	// +kubebuilder:validation:Optional
+	RequireTargetDown bool `json:"requireTargetDown,omitempty"`
+
+	// RequireNoRunningPods runs cleanup only when no pod of the target is running
+	// +kubebuilder:validation:Optional
+	RequireNoRunningPods bool `json:"requireNoRunningPods,omitempty"`
 }
 
 type TargetRef struct {
@@ -65,16 +242,134 @@ type TargetRef struct {
 	ApiVersion string `json:"apiVersion"`
 }
 
+// Scale hook phases
+const (
+	HookPhasePreScaleDown  = "preScaleDown"
+	HookPhasePostScaleDown = "postScaleDown"
+	HookPhasePreScaleUp    = "preScaleUp"
+	HookPhasePostScaleUp   = "postScaleUp"
+)
+
+// Scale hook failure policies
+const (
+	HookFailurePolicyAbort    = "Abort"
+	HookFailurePolicyContinue = "Continue"
+)
+
+// Scale hook results
+const (
+	HookResultRunning   = "Running"
+	HookResultSucceeded = "Succeeded"
+	HookResultFailed    = "Failed"
+	HookResultTimedOut  = "TimedOut"
+)
+
+// ScaleHooks defines the hooks run around scale events
+type ScaleHooks struct {
+	// Hook run before scaling down; with failurePolicy Abort a failure cancels the scale down
+	// +kubebuilder:validation:Optional
+	PreScaleDown *ScaleHook `json:"preScaleDown,omitempty"`
+
+	// Hook run after scaling down
+	// +kubebuilder:validation:Optional
+	PostScaleDown *ScaleHook `json:"postScaleDown,omitempty"`
+
+	// Hook run before scaling up; with failurePolicy Abort a failure cancels the scale up
+	// +kubebuilder:validation:Optional
+	PreScaleUp *ScaleHook `json:"preScaleUp,omitempty"`
+
+	// Hook run after scaling up
+	// +kubebuilder:validation:Optional
+	PostScaleUp *ScaleHook `json:"postScaleUp,omitempty"`
+}
+
+// ScaleHook is a Job the operator creates and waits on around a scale event
+type ScaleHook struct {
+	// Template of the Job created in the CronJobScaleDown's namespace, and owned by it, when the hook runs.
+	// Exactly one of jobTemplate and http must be set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	JobTemplate *batchv1.JobTemplateSpec `json:"jobTemplate,omitempty"`
+
+	// HTTP endpoint called when the hook runs.
+	// Exactly one of jobTemplate and http must be set.
+	// +kubebuilder:validation:Optional
+	HTTP *HTTPHook `json:"http,omitempty"`
+
+	// Maximum time to wait for the hook to finish (e.g., "5m", "1h")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="10m"
+	Timeout string `json:"timeout,omitempty"`
+
+	// What to do when the hook fails or times out: Abort skips the scale event, Continue scales anyway
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Abort;Continue
+	// +kubebuilder:default:="Abort"
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// HTTPHook POSTs a JSON description of the scale event to a URL
+type HTTPHook struct {
+	// URL the event is POSTed to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Secret key, in the CronJobScaleDown's namespace, holding the key used to sign the payload
+	// with HMAC-SHA256. The signature is sent in the X-CronJobScaleDown-Signature header.
+	// +kubebuilder:validation:Optional
+	SignatureSecretRef *SecretKeyRef `json:"signatureSecretRef,omitempty"`
+
+	// Number of retries after a failed request. Retries are made on later reconciles, after a backoff
+	// starting at 10s and doubling on every attempt, within the hook timeout.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default:=2
+	Retries int32 `json:"retries,omitempty"`
+
+	// Timeout of a single request (e.g., "10s")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="10s"
+	RequestTimeout string `json:"requestTimeout,omitempty"`
+}
+
+// SecretKeyRef selects a key of a Secret
+type SecretKeyRef struct {
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key within the Secret
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
 type CleanupConfig struct {
 	// Namespaces to search for resources to cleanup (defaults to same namespace as the CronJobScaleDown)
 	// +kubebuilder:validation:Optional
 	Namespaces []string `json:"namespaces,omitempty"`
 
+	// NamespaceSelector picks additional namespaces to clean up at run time, e.g. preview environments
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *NamespaceSelector `json:"namespaceSelector,omitempty"`
+
+	// DeleteNamespace deletes whole namespaces selected by namespaces or namespaceSelector once the
+	// cleanup annotation on the namespace expires or the namespace has been idle for idlePeriod.
+	// Only namespaces labeled cronjob-scale-down-operator/deletable=true are deleted
+	// +kubebuilder:validation:Optional
+	DeleteNamespace *NamespaceDeletion `json:"deleteNamespace,omitempty"`
+
 	// Annotation key that marks resources for cleanup
 	// +kubebuilder:validation:Required
 	AnnotationKey string `json:"annotationKey"`
 
-	// Resource types to cleanup (e.g., ["Deployment", "StatefulSet", "Service", "ConfigMap"])
+	// Resource types to cleanup (e.g., ["Deployment", "StatefulSet", "Service", "ConfigMap"]).
+	// Other kinds, including custom resources, can be given as "group/version/kind"
+	// (e.g., "cert-manager.io/v1/Certificate") or "kind.group" (e.g., "Application.argoproj.io").
+	// "HelmRelease" cleans up Helm releases as a whole, with their resources and history Secrets.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
 	ResourceTypes []string `json:"resourceTypes"`
@@ -83,6 +378,36 @@ type CleanupConfig struct {
 	// +kubebuilder:validation:Optional
 	LabelSelector map[string]string `json:"labelSelector,omitempty"`
 
+	// Selector filters resources with matchLabels and set-based matchExpressions
+	// (e.g., env in (pr, preview), or !keep). Combined with labelSelector when both are set.
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FieldSelector filters resources by field (e.g., "status.phase=Failed" for Pods). It applies to
+	// every kind, so each kind cleaned up must support its fields; runs are skipped otherwise.
+	// +kubebuilder:validation:Optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// NameRegex limits cleanup to resources whose name matches this regular expression
+	// +kubebuilder:validation:Optional
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// ExcludeNameRegex keeps resources whose name matches this regular expression
+	// +kubebuilder:validation:Optional
+	ExcludeNameRegex string `json:"excludeNameRegex,omitempty"`
+
+	// PodPhases restricts Pod cleanup to pods in these phases. Evicted matches failed pods
+	// evicted by the kubelet. Pods in other phases are never cleaned up when set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum=Failed;Succeeded;Evicted
+	PodPhases []string `json:"podPhases,omitempty"`
+
+	// JobConditions restricts Job cleanup to finished jobs with one of these conditions.
+	// Running jobs are never cleaned up when set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum=Complete;Failed
+	JobConditions []string `json:"jobConditions,omitempty"`
+
 	// DryRun mode - if true, only logs what would be deleted without actually deleting
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=false
@@ -95,9 +420,243 @@ type CleanupConfig struct {
 
 	// OrphanResourceMaxAge defines the maximum age for orphan resources before cleanup
 	// Resources older than this duration without cleanup annotation will be deleted
-	// Format: duration string (e.g., "24h", "7d", "168h")
+	// Format: duration string with optional d (day) and w (week) units (e.g., "24h", "7d", "1w2d12h")
 	// +kubebuilder:validation:Optional
 	OrphanResourceMaxAge string `json:"orphanResourceMaxAge,omitempty"`
+
+	// OrphanDetection selects which unannotated resources older than orphanResourceMaxAge are orphans:
+	// Age treats all of them as orphans, OwnerReferences only those whose owners no longer exist,
+	// and Unreferenced only ConfigMaps and Secrets that nothing in their namespace references
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Age;OwnerReferences;Unreferenced
+	// +kubebuilder:default:="Age"
+	OrphanDetection string `json:"orphanDetection,omitempty"`
+
+	// AgeSource selects the time the age of a resource is measured from, for cleanup annotation durations
+	// and orphanResourceMaxAge: creation, lastUpdate (latest managedFields timestamp, ignoring status updates
+	// and the operator's own changes), lastRollout (latest status condition time, e.g. of a Deployment
+	// rollout) or lastPodStart (latest start of the pods of a workload or Service). Resources without such
+	// activity fall back to their creation time.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=creation;lastUpdate;lastRollout;lastPodStart
+	// +kubebuilder:default:="creation"
+	AgeSource string `json:"ageSource,omitempty"`
+
+	// DateDeadline selects when a cleanup annotation holding a date only (2006-01-02) expires: StartOfDay at
+	// the midnight starting the date, EndOfDay at the midnight ending it. Dates, and RFC3339 times without an
+	// offset (2006-01-02T15:04:05), are interpreted in the timeZone of the CronJobScaleDown.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=StartOfDay;EndOfDay
+	// +kubebuilder:default:="StartOfDay"
+	DateDeadline string `json:"dateDeadline,omitempty"`
+
+	// MaxDeletionsPerRun blocks a cleanup run that would delete more resources than this,
+	// until the run is acknowledged with the cleanup-acknowledged annotation (0 disables the limit)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxDeletionsPerRun int32 `json:"maxDeletionsPerRun,omitempty"`
+
+	// MaxDeletionPercent blocks a cleanup run that would delete more than this percentage of the
+	// resources it matched, until the run is acknowledged (0 disables the limit)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxDeletionPercent int32 `json:"maxDeletionPercent,omitempty"`
+
+	// UnreferencedConfigs enables an analysis pass that cleans up ConfigMaps and Secrets that nothing
+	// in their namespace references, independently of resourceTypes. Resources carrying the cleanup
+	// annotation are left to it.
+	// +kubebuilder:validation:Optional
+	UnreferencedConfigs *UnreferencedConfigsCleanup `json:"unreferencedConfigs,omitempty"`
+
+	// Cascade deletes the objects related to each Deployment, StatefulSet or Job cleaned up, such as its
+	// Services, Ingresses, ConfigMaps, HPA and PDB, together with it as one group
+	// +kubebuilder:validation:Optional
+	Cascade *CascadeCleanup `json:"cascade,omitempty"`
+
+	// WarnBefore gives advance notice of resources whose cleanup annotation expires within this window
+	// (e.g., "24h", "2d"): they get a PendingDeletion Warning Event and a pending-deletion-at annotation,
+	// and are listed in status.upcomingDeletions. It must be at least the longest interval between runs of
+	// the cleanup schedule, and can't be used with RBAC kinds.
+	// +kubebuilder:validation:Optional
+	WarnBefore string `json:"warnBefore,omitempty"`
+
+	// QuarantinePeriod enables two-phase cleanup: a resource due for cleanup is first quarantined
+	// (labeled, scaled to 0 and, for Services, detached from its pods) and only deleted once it has been
+	// in quarantine for this long (e.g., "24h", "3d"). Removing the quarantine label rescues it.
+	// Only the built-in kinds other than RBAC objects, and custom kinds the operator may patch, can be quarantined.
+	// +kubebuilder:validation:Optional
+	QuarantinePeriod string `json:"quarantinePeriod,omitempty"`
+
+	// RescuePeriod is how long a resource rescued from quarantine is exempt from being quarantined
+	// again (e.g., "7d"), measured from its rescue
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="30d"
+	RescuePeriod string `json:"rescuePeriod,omitempty"`
+
+	// Archive saves every object as YAML before it is deleted, so that it can be restored.
+	// A run whose archive can't be written deletes nothing.
+	// +kubebuilder:validation:Optional
+	Archive *CleanupArchive `json:"archive,omitempty"`
+
+	// PropagationPolicy controls how dependents of deleted resources are handled
+	// (defaults to the API server's behaviour for the kind, usually Background)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Foreground;Background;Orphan
+	PropagationPolicy string `json:"propagationPolicy,omitempty"`
+
+	// GracePeriodSeconds overrides the termination grace period of deleted resources
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// WaitForDeletion counts each resource as deleted only once it is gone, e.g. once its dependents are
+	// removed with Foreground propagation. Resources still present are tracked in status.pendingDeletions
+	// and checked on later reconciles.
+	// +kubebuilder:validation:Optional
+	WaitForDeletion bool `json:"waitForDeletion,omitempty"`
+
+	// DeletionTimeout is how long after the run a resource tracked with waitForDeletion is reported as failed
+	// if it still exists (e.g., "2m")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="2m"
+	DeletionTimeout string `json:"deletionTimeout,omitempty"`
+
+	// HistoryLimit is the number of cleanup run reports kept in status.cleanupHistory
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	// +kubebuilder:default:=5
+	HistoryLimit int32 `json:"historyLimit,omitempty"`
+
+	// Paging lists resources in pages read from the API server, and lets a long cleanup run resume
+	// across reconciles, for namespaces holding too many resources to list at once
+	// +kubebuilder:validation:Optional
+	Paging *CleanupPaging `json:"paging,omitempty"`
+
+	// DeletesPerSecond bounds the rate of deletions of a cleanup run (0 disables the limit). A reconcile plans
+	// the deletions the rate allows for now, at most a second's worth, and the run continues once the rate
+	// allows more, as with paging.maxPagesPerRun; paging applies with its defaults when it isn't set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	DeletesPerSecond int32 `json:"deletesPerSecond,omitempty"`
+}
+
+// NamespaceSelector selects namespaces by labels and name. Both criteria must match when set.
+type NamespaceSelector struct {
+	// MatchLabels selects namespaces carrying all of these labels
+	// +kubebuilder:validation:Optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// NameRegex selects namespaces whose name matches this regular expression (e.g., "^pr-[0-9]+$")
+	// +kubebuilder:validation:Optional
+	NameRegex string `json:"nameRegex,omitempty"`
+}
+
+// NamespaceDeletion configures the deletion of whole namespaces. The TTL is read from the
+// cleanup annotation (annotationKey) on the namespace, in any of its formats.
+// kube-system, kube-public, kube-node-lease, default and the CronJobScaleDown's namespace are never deleted.
+type NamespaceDeletion struct {
+	// IdlePeriod deletes namespaces whose workloads (Deployments, StatefulSets, DaemonSets, Jobs,
+	// CronJobs and Pods) have not been created or updated for this long (e.g., "72h", "7d")
+	// +kubebuilder:validation:Optional
+	IdlePeriod string `json:"idlePeriod,omitempty"`
+}
+
+// UnreferencedConfigsCleanup configures the cleanup of unreferenced ConfigMaps and Secrets.
+// References are collected from the pod templates of Deployments, ReplicaSets, StatefulSets,
+// DaemonSets, Jobs and CronJobs, from Pods, ServiceAccounts and Ingress TLS.
+type UnreferencedConfigsCleanup struct {
+	// Kinds to analyse; each kind must be opted in
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum=ConfigMap;Secret
+	Kinds []string `json:"kinds"`
+
+	// GraceAge is how old an unreferenced ConfigMap or Secret must be before it is cleaned up (e.g., "24h", "7d")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="24h"
+	GraceAge string `json:"graceAge,omitempty"`
+}
+
+// CascadeCleanup configures which objects are deleted together with a workload. An object is related to a
+// workload when it has the same app.kubernetes.io/instance label, when its selector matches the workload's
+// pod template (Services, PDBs), when it scales the workload (HPAs), when it routes to a related Service
+// (Ingresses), or when the pod template references it and no other workload does (ConfigMaps, Secrets).
+type CascadeCleanup struct {
+	// Kinds of related objects to delete (defaults to all of them)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum=Service;Ingress;ConfigMap;Secret;HorizontalPodAutoscaler;PodDisruptionBudget
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// CleanupPaging configures paged listing of the resources to clean up. Pages are read from the API server
+// with limit and continue; HelmRelease, the related objects of cascade cleanup, unreferencedConfigs and
+// archive pruning list whole namespaces at once.
+type CleanupPaging struct {
+	// PageSize is the number of resources listed per request
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=500
+	PageSize int32 `json:"pageSize,omitempty"`
+
+	// MaxPagesPerRun stops a cleanup run after this many pages; the run deletes what it found and the next
+	// reconcile resumes from status.cleanupContinuation
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=10
+	MaxPagesPerRun int32 `json:"maxPagesPerRun,omitempty"`
+}
+
+// CleanupContinuation is where a paged cleanup run stopped, to be resumed by the next reconcile
+type CleanupContinuation struct {
+	// ResourceType and Namespace identify the listing the run stopped in
+	ResourceType string `json:"resourceType"`
+	Namespace    string `json:"namespace,omitempty"`
+
+	// Continue is the continuation token of the next page, empty to start the listing from its first page
+	Continue string `json:"continue,omitempty"`
+
+	// StartedAt is when the cleanup run being resumed started
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// Planned and Matched are the deletions planned and the resources matched so far by the run, which the
+	// deletion limits apply to across all its parts
+	// +optional
+	Planned int32 `json:"planned,omitempty"`
+	// +optional
+	Matched int32 `json:"matched,omitempty"`
+
+	// Acknowledged is the number of planned deletions acknowledged for the run. Its later parts are only
+	// blocked again once the run plans more deletions than that.
+	// +optional
+	Acknowledged int32 `json:"acknowledged,omitempty"`
+}
+
+// CleanupArchive configures where cleaned up objects are archived and for how long.
+// Objects are stored without status, managedFields, resourceVersion and other server-set metadata.
+type CleanupArchive struct {
+	// Sink stores archives as compressed Secrets, ConfigMaps or files in a local directory.
+	// ConfigMap can't be used when the cleanup may delete Secrets.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Secret;ConfigMap;Directory
+	// +kubebuilder:default:="Secret"
+	Sink string `json:"sink,omitempty"`
+
+	// Namespace of the archive Secrets or ConfigMaps (defaults to the CronJobScaleDown's namespace).
+	// Only the CronJobScaleDown's namespace and the operator's --archive-namespace are allowed.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Path is the directory archives are written to with the Directory sink, relative to the operator's
+	// --archive-root, usually a PersistentVolumeClaim mounted in the operator's pod. It can't contain "..".
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// Retention is how long archives are kept (e.g., "720h", "30d")
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="30d"
+	Retention string `json:"retention,omitempty"`
 }
 
 // CronJobScaleDownStatus defines the observed state of CronJobScaleDown.
@@ -116,6 +675,187 @@ type CronJobScaleDownStatus struct {
 
 	// LastCleanupResourceCount is the number of resources cleaned up in the last cleanup operation
 	LastCleanupResourceCount int32 `json:"lastCleanupResourceCount,omitempty"`
+
+	// Conditions represent the latest available observations of the CronJobScaleDown's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastCleanupSkipped lists resources the last cleanup operation considered but kept, with the reason
+	// (truncated to the first 50)
+	LastCleanupSkipped []SkippedResource `json:"lastCleanupSkipped,omitempty"`
+
+	// QuarantinedResources lists the resources in quarantine with the time they are deleted at
+	// (truncated to the first 50)
+	QuarantinedResources []QuarantinedResource `json:"quarantinedResources,omitempty"`
+
+	// UpcomingDeletions lists the resources due for cleanup within the warnBefore window, soonest first
+	// (truncated to the first 50)
+	UpcomingDeletions []UpcomingDeletion `json:"upcomingDeletions,omitempty"`
+
+	// CleanupHistory holds the reports of the latest cleanup runs, newest first (up to cleanupConfig.historyLimit)
+	CleanupHistory []CleanupRun `json:"cleanupHistory,omitempty"`
+
+	// CleanupContinuation is set while a paged cleanup run paused until the next reconcile is resumed
+	CleanupContinuation *CleanupContinuation `json:"cleanupContinuation,omitempty"`
+
+	// PendingDeletions lists the resources deleted with waitForDeletion that haven't disappeared yet
+	// (the first 50 of a run)
+	PendingDeletions []PendingDeletion `json:"pendingDeletions,omitempty"`
+
+	// LastCleanupArchive is the archive the last cleanup operation wrote the deleted objects to
+	LastCleanupArchive string `json:"lastCleanupArchive,omitempty"`
+
+	// LastAbortedScaleDownTime is the time when a scale down was last cancelled by a hook
+	LastAbortedScaleDownTime metav1.Time `json:"lastAbortedScaleDownTime,omitempty"`
+
+	// LastAbortedScaleUpTime is the time when a scale up was last cancelled by a hook
+	LastAbortedScaleUpTime metav1.Time `json:"lastAbortedScaleUpTime,omitempty"`
+
+	// Hooks holds the latest run of each configured scale hook
+	Hooks []HookStatus `json:"hooks,omitempty"`
+
+	// Drain describes a scale down currently deferred by the drain condition
+	Drain *DrainStatus `json:"drain,omitempty"`
+}
+
+// DrainStatus records a scale down waiting for the drain condition
+type DrainStatus struct {
+	// ScheduledTime is the schedule time of the deferred scale down
+	ScheduledTime metav1.Time `json:"scheduledTime,omitempty"`
+
+	// Since is when scale down was first deferred
+	Since metav1.Time `json:"since,omitempty"`
+
+	// LastCheckTime is when the drain condition was last checked
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Postponements is the number of times scale down has been deferred
+	Postponements int32 `json:"postponements,omitempty"`
+
+	// Reason explains why scale down is deferred
+	Reason string `json:"reason,omitempty"`
+}
+
+// SkippedResource is a resource the cleanup considered but kept, with the reason
+type SkippedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// QuarantinedResource is a resource in quarantine, deleted by the cleanup after DeleteAfter
+type QuarantinedResource struct {
+	Kind        string      `json:"kind"`
+	Namespace   string      `json:"namespace,omitempty"`
+	Name        string      `json:"name"`
+	DeleteAfter metav1.Time `json:"deleteAfter"`
+}
+
+// UpcomingDeletion is a resource whose cleanup annotation expires soon
+type UpcomingDeletion struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	DeleteAt  metav1.Time `json:"deleteAt"`
+}
+
+// PendingDeletion is a resource deleted by a cleanup run with waitForDeletion that still exists. It is
+// reported as deleted in the run's history once it disappears, or as failed at its deadline.
+type PendingDeletion struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Name       string    `json:"name"`
+	UID        types.UID `json:"uid,omitempty"`
+
+	// RunTime is the time of the cleanup run that deleted the resource
+	RunTime metav1.Time `json:"runTime"`
+
+	// Deadline is when the resource is reported as failed if it still exists
+	Deadline metav1.Time `json:"deadline"`
+}
+
+// CleanupRun is the report of a cleanup run
+type CleanupRun struct {
+	// Time is when the run was executed
+	Time metav1.Time `json:"time"`
+
+	// DryRun is true when the run only reported what it would do
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Deleted is the number of resources deleted (or that would be deleted in dry-run mode)
+	Deleted int32 `json:"deleted"`
+
+	// Quarantined is the number of resources quarantined (or that would be quarantined in dry-run mode)
+	Quarantined int32 `json:"quarantined,omitempty"`
+
+	// Failed is the number of resources that could not be deleted or quarantined
+	Failed int32 `json:"failed,omitempty"`
+
+	// Resources lists what the run did to each resource (truncated to the first 50)
+	Resources []CleanupRecord `json:"resources,omitempty"`
+
+	// Truncated is the number of resources left out of Resources
+	Truncated int32 `json:"truncated,omitempty"`
+
+	// SkippedReason is why the run was skipped by the cleanup precondition without planning any deletion
+	SkippedReason string `json:"skippedReason,omitempty"`
+}
+
+// CleanupRecord is what a cleanup run did to a resource, and why
+type CleanupRecord struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// Action taken (Deleted, Deleting, WouldDelete, Quarantined, WouldQuarantine, Failed, Skipped)
+	Action string `json:"action"`
+
+	// Reason the resource was cleaned up (annotation, immediate, orphan age, unreferenced, idle namespace, cascade)
+	// or kept
+	Reason string `json:"reason,omitempty"`
+
+	// Message holds the error of a failed action
+	Message string `json:"message,omitempty"`
+
+	// Group is the workload (e.g., "Deployment/api") whose cascade group the resource was deleted with
+	Group string `json:"group,omitempty"`
+}
+
+// HookStatus records a run of a scale hook
+type HookStatus struct {
+	// Phase of the hook (preScaleDown, postScaleDown, preScaleUp, postScaleUp)
+	Phase string `json:"phase"`
+
+	// JobName is the name of the Job created for the hook
+	JobName string `json:"jobName,omitempty"`
+
+	// Result of the hook (Running, Succeeded, Failed, TimedOut)
+	Result string `json:"result"`
+
+	// ScheduledTime is the schedule time of the scale event the hook ran for
+	ScheduledTime metav1.Time `json:"scheduledTime,omitempty"`
+
+	// StartTime is when the hook was started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the hook finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Duration of the hook run (e.g., "42s")
+	Duration string `json:"duration,omitempty"`
+
+	// Message explains the result
+	Message string `json:"message,omitempty"`
+
+	// Attempts is the number of requests made by an HTTP hook
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// NextAttemptTime is when a failed HTTP hook request is retried
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,9 +21,47 @@ limitations under the License.
 package v1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CascadeCleanup) DeepCopyInto(out *CascadeCleanup) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CascadeCleanup.
+func (in *CascadeCleanup) DeepCopy() *CascadeCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(CascadeCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupArchive) DeepCopyInto(out *CleanupArchive) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupArchive.
+func (in *CleanupArchive) DeepCopy() *CleanupArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 	*out = *in
@@ -44,6 +82,56 @@ func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 			(*out)[key] = val
 		}
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodPhases != nil {
+		in, out := &in.PodPhases, &out.PodPhases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JobConditions != nil {
+		in, out := &in.JobConditions, &out.JobConditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(NamespaceSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeleteNamespace != nil {
+		in, out := &in.DeleteNamespace, &out.DeleteNamespace
+		*out = new(NamespaceDeletion)
+		**out = **in
+	}
+	if in.UnreferencedConfigs != nil {
+		in, out := &in.UnreferencedConfigs, &out.UnreferencedConfigs
+		*out = new(UnreferencedConfigsCleanup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cascade != nil {
+		in, out := &in.Cascade, &out.Cascade
+		*out = new(CascadeCleanup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(CleanupArchive)
+		**out = **in
+	}
+	if in.Paging != nil {
+		in, out := &in.Paging, &out.Paging
+		*out = new(CleanupPaging)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupConfig.
@@ -56,6 +144,219 @@ func (in *CleanupConfig) DeepCopy() *CleanupConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupContinuation) DeepCopyInto(out *CleanupContinuation) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupContinuation.
+func (in *CleanupContinuation) DeepCopy() *CleanupContinuation {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupContinuation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupPaging) DeepCopyInto(out *CleanupPaging) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPaging.
+func (in *CleanupPaging) DeepCopy() *CleanupPaging {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupPaging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupPolicy) DeepCopyInto(out *CleanupPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPolicy.
+func (in *CleanupPolicy) DeepCopy() *CleanupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CleanupPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupPolicyList) DeepCopyInto(out *CleanupPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CleanupPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPolicyList.
+func (in *CleanupPolicyList) DeepCopy() *CleanupPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CleanupPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupPolicySpec) DeepCopyInto(out *CleanupPolicySpec) {
+	*out = *in
+	if in.ProtectedNamespaces != nil {
+		in, out := &in.ProtectedNamespaces, &out.ProtectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedKinds != nil {
+		in, out := &in.ProtectedKinds, &out.ProtectedKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedNamePatterns != nil {
+		in, out := &in.ProtectedNamePatterns, &out.ProtectedNamePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedLabels != nil {
+		in, out := &in.ProtectedLabels, &out.ProtectedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProtectedAnnotations != nil {
+		in, out := &in.ProtectedAnnotations, &out.ProtectedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPolicySpec.
+func (in *CleanupPolicySpec) DeepCopy() *CleanupPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupPrecondition) DeepCopyInto(out *CleanupPrecondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPrecondition.
+func (in *CleanupPrecondition) DeepCopy() *CleanupPrecondition {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupPrecondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupRecord) DeepCopyInto(out *CleanupRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupRecord.
+func (in *CleanupRecord) DeepCopy() *CleanupRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupRun) DeepCopyInto(out *CleanupRun) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]CleanupRecord, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupRun.
+func (in *CleanupRun) DeepCopy() *CleanupRun {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResources.
+func (in *ContainerResources) DeepCopy() *ContainerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronJobScaleDown) DeepCopyInto(out *CronJobScaleDown) {
 	*out = *in
@@ -128,6 +429,26 @@ func (in *CronJobScaleDownSpec) DeepCopyInto(out *CronJobScaleDownSpec) {
 		*out = new(CleanupConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CleanupPrecondition != nil {
+		in, out := &in.CleanupPrecondition, &out.CleanupPrecondition
+		*out = new(CleanupPrecondition)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(ScaleHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainCondition != nil {
+		in, out := &in.DrainCondition, &out.DrainCondition
+		*out = new(DrainCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RightsizeProfile != nil {
+		in, out := &in.RightsizeProfile, &out.RightsizeProfile
+		*out = new(RightsizeProfile)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobScaleDownSpec.
@@ -146,6 +467,65 @@ func (in *CronJobScaleDownStatus) DeepCopyInto(out *CronJobScaleDownStatus) {
 	in.LastScaleDownTime.DeepCopyInto(&out.LastScaleDownTime)
 	in.LastScaleUpTime.DeepCopyInto(&out.LastScaleUpTime)
 	in.LastCleanupTime.DeepCopyInto(&out.LastCleanupTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCleanupSkipped != nil {
+		in, out := &in.LastCleanupSkipped, &out.LastCleanupSkipped
+		*out = make([]SkippedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuarantinedResources != nil {
+		in, out := &in.QuarantinedResources, &out.QuarantinedResources
+		*out = make([]QuarantinedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpcomingDeletions != nil {
+		in, out := &in.UpcomingDeletions, &out.UpcomingDeletions
+		*out = make([]UpcomingDeletion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CleanupHistory != nil {
+		in, out := &in.CleanupHistory, &out.CleanupHistory
+		*out = make([]CleanupRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CleanupContinuation != nil {
+		in, out := &in.CleanupContinuation, &out.CleanupContinuation
+		*out = new(CleanupContinuation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingDeletions != nil {
+		in, out := &in.PendingDeletions, &out.PendingDeletions
+		*out = make([]PendingDeletion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastAbortedScaleDownTime.DeepCopyInto(&out.LastAbortedScaleDownTime)
+	in.LastAbortedScaleUpTime.DeepCopyInto(&out.LastAbortedScaleUpTime)
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobScaleDownStatus.
@@ -158,6 +538,273 @@ func (in *CronJobScaleDownStatus) DeepCopy() *CronJobScaleDownStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainCondition) DeepCopyInto(out *DrainCondition) {
+	*out = *in
+	if in.JobSelector != nil {
+		in, out := &in.JobSelector, &out.JobSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainCondition.
+func (in *DrainCondition) DeepCopy() *DrainCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+	in.Since.DeepCopyInto(&out.Since)
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPHook) DeepCopyInto(out *HTTPHook) {
+	*out = *in
+	if in.SignatureSecretRef != nil {
+		in, out := &in.SignatureSecretRef, &out.SignatureSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHook.
+func (in *HTTPHook) DeepCopy() *HTTPHook {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceDeletion) DeepCopyInto(out *NamespaceDeletion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceDeletion.
+func (in *NamespaceDeletion) DeepCopy() *NamespaceDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelector.
+func (in *NamespaceSelector) DeepCopy() *NamespaceSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingDeletion) DeepCopyInto(out *PendingDeletion) {
+	*out = *in
+	in.RunTime.DeepCopyInto(&out.RunTime)
+	in.Deadline.DeepCopyInto(&out.Deadline)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingDeletion.
+func (in *PendingDeletion) DeepCopy() *PendingDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuarantinedResource) DeepCopyInto(out *QuarantinedResource) {
+	*out = *in
+	in.DeleteAfter.DeepCopyInto(&out.DeleteAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantinedResource.
+func (in *QuarantinedResource) DeepCopy() *QuarantinedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(QuarantinedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RightsizeProfile) DeepCopyInto(out *RightsizeProfile) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerResources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RightsizeProfile.
+func (in *RightsizeProfile) DeepCopy() *RightsizeProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(RightsizeProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleHook) DeepCopyInto(out *ScaleHook) {
+	*out = *in
+	if in.JobTemplate != nil {
+		in, out := &in.JobTemplate, &out.JobTemplate
+		*out = new(batchv1.JobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPHook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleHook.
+func (in *ScaleHook) DeepCopy() *ScaleHook {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleHooks) DeepCopyInto(out *ScaleHooks) {
+	*out = *in
+	if in.PreScaleDown != nil {
+		in, out := &in.PreScaleDown, &out.PreScaleDown
+		*out = new(ScaleHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostScaleDown != nil {
+		in, out := &in.PostScaleDown, &out.PostScaleDown
+		*out = new(ScaleHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreScaleUp != nil {
+		in, out := &in.PreScaleUp, &out.PreScaleUp
+		*out = new(ScaleHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostScaleUp != nil {
+		in, out := &in.PostScaleUp, &out.PostScaleUp
+		*out = new(ScaleHook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleHooks.
+func (in *ScaleHooks) DeepCopy() *ScaleHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedResource) DeepCopyInto(out *SkippedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedResource.
+func (in *SkippedResource) DeepCopy() *SkippedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetRef) DeepCopyInto(out *TargetRef) {
 	*out = *in
@@ -172,3 +819,39 @@ func (in *TargetRef) DeepCopy() *TargetRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnreferencedConfigsCleanup) DeepCopyInto(out *UnreferencedConfigsCleanup) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnreferencedConfigsCleanup.
+func (in *UnreferencedConfigsCleanup) DeepCopy() *UnreferencedConfigsCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(UnreferencedConfigsCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpcomingDeletion) DeepCopyInto(out *UpcomingDeletion) {
+	*out = *in
+	in.DeleteAt.DeepCopyInto(&out.DeleteAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpcomingDeletion.
+func (in *UpcomingDeletion) DeepCopy() *UpcomingDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(UpcomingDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,6 +33,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -39,6 +42,7 @@ import (
 
 	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
 	"github.com/z4ck404/cronjob-scale-down-operator/internal/controller"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
 	"github.com/z4ck404/cronjob-scale-down-operator/internal/webui"
 	// +kubebuilder:scaffold:imports
 )
@@ -65,6 +69,11 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var webuiAddr string
+	var maxConcurrentScaleUps int
+	var scaleStaggerWindow time.Duration
+	var scaleUpSlotTimeout time.Duration
+	var archiveSettings utils.ArchiveSettings
+	var archiveSigningKeyFile string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -85,6 +94,20 @@ func main() {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&webuiAddr, "webui-addr", ":8082",
 		"The address the web UI binds to. Use :8443 for HTTPS or :8080 for HTTP.")
+	flag.IntVar(&maxConcurrentScaleUps, "max-concurrent-scale-ups", 0,
+		"Maximum number of scale-ups allowed to run at the same time across all CronJobScaleDown resources. "+
+			"A scale-up holds its slot until the target is ready. 0 means unlimited.")
+	flag.DurationVar(&scaleStaggerWindow, "scale-stagger-window", 0,
+		"Spread scale events sharing a schedule over this window using a deterministic per-resource offset. "+
+			"0 disables staggering.")
+	flag.DurationVar(&scaleUpSlotTimeout, "scale-up-slot-timeout", 5*time.Minute,
+		"Release a scale-up slot after this long even if the target has not become ready.")
+	flag.StringVar(&archiveSettings.Namespace, "archive-namespace", "",
+		"Namespace CronJobScaleDowns may keep cleanup archives in besides their own namespace.")
+	flag.StringVar(&archiveSettings.Root, "archive-root", utils.DefaultArchiveRoot,
+		"Directory the paths of the Directory archive sink are relative to, e.g. a mounted PersistentVolumeClaim.")
+	flag.StringVar(&archiveSigningKeyFile, "archive-signing-key-file", "",
+		"File holding the key cleanup archives are signed with. Archives can only be restored when it is set.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -93,6 +116,15 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if archiveSigningKeyFile != "" {
+		key, err := os.ReadFile(archiveSigningKeyFile)
+		if err != nil || len(bytes.TrimSpace(key)) == 0 {
+			setupLog.Error(err, "unable to read archive signing key", "file", archiveSigningKeyFile)
+			os.Exit(1)
+		}
+		archiveSettings.SigningKey = bytes.TrimSpace(key)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -206,9 +238,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	var scaleLimiter *controller.ScaleLimiter
+	if maxConcurrentScaleUps > 0 || scaleStaggerWindow > 0 {
+		setupLog.Info("Limiting scale operations",
+			"max-concurrent-scale-ups", maxConcurrentScaleUps,
+			"scale-stagger-window", scaleStaggerWindow,
+			"scale-up-slot-timeout", scaleUpSlotTimeout)
+		scaleLimiter = controller.NewScaleLimiter(maxConcurrentScaleUps, scaleStaggerWindow, scaleUpSlotTimeout)
+	}
+
 	if err = (&controller.CronJobScaleDownReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          client.WithFieldOwner(mgr.GetClient(), utils.FieldManager),
+		Scheme:          mgr.GetScheme(),
+		ScaleLimiter:    scaleLimiter,
+		Recorder:        mgr.GetEventRecorderFor("cronjobscaledown-controller"),
+		APIReader:       mgr.GetAPIReader(),
+		ArchiveSettings: archiveSettings,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CronJobScaleDown")
 		os.Exit(1)
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+// validateCleanupArchive validates the archive sink and retention
+func validateCleanupArchive(cleanupConfig *cronschedulesv1.CleanupConfig) error {
+	archive := cleanupConfig.Archive
+	if archive == nil {
+		return nil
+	}
+
+	switch archive.Sink {
+	case "", cronschedulesv1.ArchiveSinkSecret:
+	case cronschedulesv1.ArchiveSinkConfigMap:
+		// Archiving Secrets into ConfigMaps would expose their data
+		deletesSecrets := slices.Contains(cleanupConfig.ResourceTypes, "Secret")
+		if unreferenced := cleanupConfig.UnreferencedConfigs; unreferenced != nil {
+			deletesSecrets = deletesSecrets || len(unreferenced.Kinds) == 0 || slices.Contains(unreferenced.Kinds, "Secret")
+		}
+		if cascade := cleanupConfig.Cascade; cascade != nil {
+			deletesSecrets = deletesSecrets || len(cascade.Kinds) == 0 || slices.Contains(cascade.Kinds, "Secret")
+		}
+		if deletesSecrets {
+			return fmt.Errorf("archive sink ConfigMap can't be used when cleanup may delete Secrets")
+		}
+	case cronschedulesv1.ArchiveSinkDirectory:
+		if err := utils.ValidateArchivePath(archive.Path); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported archive sink: %s", archive.Sink)
+	}
+
+	if archive.Retention != "" {
+		if _, err := utils.ParseDuration(archive.Retention); err != nil {
+			return fmt.Errorf("invalid archive retention format: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateArchiveNamespace checks that archive Secrets and ConfigMaps are kept in the CronJobScaleDown's
+// namespace or the archive namespace configured for the operator, so that CronJobScaleDown authors can't
+// write to or restore from other namespaces
+func (r *CronJobScaleDownReconciler) validateArchiveNamespace(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	archive := cronJobScaleDown.Spec.CleanupConfig.Archive
+	if archive == nil || archive.Namespace == "" || archive.Namespace == cronJobScaleDown.Namespace {
+		return nil
+	}
+	if r.ArchiveSettings.Namespace != "" && archive.Namespace == r.ArchiveSettings.Namespace {
+		return nil
+	}
+	return fmt.Errorf("archive namespace %s is neither the CronJobScaleDown's namespace nor the operator's archive namespace", archive.Namespace)
+}
+
+// archiveCleanup archives the planned deletions and prunes expired archives. It returns the name of the
+// archive written, if any; the run must not delete anything when it returns an error.
+func (r *CronJobScaleDownReconciler) archiveCleanup(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, plan *utils.CleanupPlan, now time.Time) (string, error) {
+	logger := log.FromContext(ctx)
+	cleanupConfig := cronJobScaleDown.Spec.CleanupConfig
+	if cleanupConfig.Archive == nil || cleanupConfig.DryRun {
+		return "", nil
+	}
+
+	owner := client.ObjectKeyFromObject(cronJobScaleDown)
+	name, err := k8sClient.ArchivePlan(ctx, plan, cleanupConfig.Archive, owner, now)
+	if err != nil {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "CleanupArchiveFailed",
+			fmt.Sprintf("Cleanup skipped, the resources could not be archived: %v", err))
+		return "", fmt.Errorf("failed to archive cleanup: %w", err)
+	}
+	if name != "" {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "CleanupArchived",
+			fmt.Sprintf("Archived %d resources to %s", len(plan.Deletions), name))
+	}
+
+	if _, err := k8sClient.PruneArchives(ctx, cleanupConfig.Archive, owner, now); err != nil {
+		logger.Error(err, "Failed to prune cleanup archives")
+	}
+	return name, nil
+}
+
+// restoreArchive restores the archive named by the restore annotation and removes the annotation,
+// so that a restore is attempted once
+func (r *CronJobScaleDownReconciler) restoreArchive(ctx context.Context, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) {
+	logger := log.FromContext(ctx)
+	name := cronJobScaleDown.Annotations[cronschedulesv1.AnnotationRestoreArchive]
+
+	if cronJobScaleDown.Spec.CleanupConfig == nil || name == "" {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "ArchiveRestoreFailed",
+			"Restore requires an archive name and cleanupConfig.archive")
+	} else {
+		k8sClient := &utils.K8sClient{Client: r.Client, Recorder: r.Recorder, APIReader: r.APIReader, ArchiveSettings: r.ArchiveSettings}
+		result, err := k8sClient.RestoreArchive(ctx, cronJobScaleDown.Spec.CleanupConfig, client.ObjectKeyFromObject(cronJobScaleDown), name)
+		if err != nil {
+			logger.Error(err, "Failed to restore cleanup archive", "archive", name)
+			r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "ArchiveRestoreFailed",
+				fmt.Sprintf("Restored %d resources from %s, %d already existed: %v", result.Restored, name, result.Existing, err))
+		} else {
+			r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "ArchiveRestored",
+				fmt.Sprintf("Restored %d resources from %s, %d already existed", result.Restored, name, result.Existing))
+		}
+	}
+
+	if err := r.removeAnnotation(ctx, cronJobScaleDown, cronschedulesv1.AnnotationRestoreArchive); err != nil {
+		logger.Error(err, "Failed to remove restore annotation")
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+// checkCleanupLimits checks the cleanup plan against the deletion limits and maintains the CleanupBlocked
+// condition. It reports whether the run may proceed and whether it proceeds because it was acknowledged.
+// A blocked run is only acknowledged by an annotation whose value is the number of planned deletions shown
+// in the condition, so an acknowledgment left over from a different plan does not let a larger run through.
+// A paged run is checked with its totals so far. Once acknowledged, its later parts proceed while the run's
+// planned deletions stay within the acknowledged number, and are blocked again when they go above it.
+func (r *CronJobScaleDownReconciler) checkCleanupLimits(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, plan *utils.CleanupPlan) (proceed, acknowledged bool) {
+	cleanupConfig := cronJobScaleDown.Spec.CleanupConfig
+	acknowledgment, acknowledged := cronJobScaleDown.Annotations[cronschedulesv1.AnnotationCleanupAcknowledged]
+
+	var blocked *utils.CleanupBlockedError
+	if err := plan.CheckLimits(cleanupConfig); !errors.As(err, &blocked) {
+		if cleanupConfig.MaxDeletionsPerRun > 0 || cleanupConfig.MaxDeletionPercent > 0 ||
+			meta.FindStatusCondition(cronJobScaleDown.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked) != nil {
+			planned, matched := plan.Totals()
+			r.setCleanupBlockedCondition(cronJobScaleDown, metav1.ConditionFalse, "WithinLimits",
+				fmt.Sprintf("%d of %d matched resources planned for deletion", planned, matched))
+		}
+		return true, acknowledged
+	}
+
+	if blocked.Planned <= plan.Acknowledged() {
+		r.setCleanupBlockedCondition(cronJobScaleDown, metav1.ConditionFalse, "Acknowledged", blocked.Reason)
+		return true, false
+	}
+
+	planned := strconv.Itoa(blocked.Planned)
+	if acknowledgment == planned {
+		plan.Acknowledge(blocked.Planned)
+		r.setCleanupBlockedCondition(cronJobScaleDown, metav1.ConditionFalse, "Acknowledged", blocked.Reason)
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "CleanupAcknowledged",
+			fmt.Sprintf("Proceeding with acknowledged cleanup: %s", blocked.Reason))
+		return true, true
+	}
+
+	message := fmt.Sprintf("%s; annotate the CronJobScaleDown with %s=%s to proceed",
+		blocked.Reason, cronschedulesv1.AnnotationCleanupAcknowledged, planned)
+	if acknowledged {
+		message = fmt.Sprintf("%s; the acknowledgment %q does not match the %s planned deletions, annotate the CronJobScaleDown with %s=%s to proceed",
+			blocked.Reason, acknowledgment, planned, cronschedulesv1.AnnotationCleanupAcknowledged, planned)
+	}
+	if r.setCleanupBlockedCondition(cronJobScaleDown, metav1.ConditionTrue, "DeletionLimitExceeded", message) {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "CleanupBlocked", message)
+	}
+	return false, false
+}
+
+// setCleanupBlockedCondition sets the CleanupBlocked condition and reports whether its status changed
+func (r *CronJobScaleDownReconciler) setCleanupBlockedCondition(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, status metav1.ConditionStatus, reason, message string) bool {
+	previous := meta.FindStatusCondition(cronJobScaleDown.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)
+	changed := previous == nil || previous.Status != status
+	meta.SetStatusCondition(&cronJobScaleDown.Status.Conditions, metav1.Condition{
+		Type:               cronschedulesv1.ConditionTypeCleanupBlocked,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: cronJobScaleDown.Generation,
+	})
+	return changed
+}
+
+// consumeCleanupAcknowledgment removes the acknowledgment annotation so that it applies to a single run
+func (r *CronJobScaleDownReconciler) consumeCleanupAcknowledgment(ctx context.Context, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	return r.removeAnnotation(ctx, cronJobScaleDown, cronschedulesv1.AnnotationCleanupAcknowledged)
+}
+
+// removeAnnotation removes a one-shot annotation from the CronJobScaleDown
+func (r *CronJobScaleDownReconciler) removeAnnotation(ctx context.Context, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, key string) error {
+	// Patch a copy so the status changes made during this reconcile are kept for the status update
+	updated := cronJobScaleDown.DeepCopy()
+	patch := client.MergeFrom(updated.DeepCopy())
+	delete(updated.Annotations, key)
+	if err := r.Patch(ctx, updated, patch); err != nil {
+		return err
+	}
+
+	delete(cronJobScaleDown.Annotations, key)
+	cronJobScaleDown.ResourceVersion = updated.ResourceVersion
+	return nil
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+var _ = Describe("Cleanup deletion limits", func() {
+	var (
+		reconciler *CronJobScaleDownReconciler
+		recorder   *record.FakeRecorder
+		k8sClient  *utils.K8sClient
+		cr         *cronschedulesv1.CronJobScaleDown
+		now        time.Time
+	)
+
+	configMapCount := func() int {
+		configMaps := &corev1.ConfigMapList{}
+		Expect(k8sClient.List(ctx, configMaps, client.InNamespace("default"))).To(Succeed())
+		return len(configMaps.Items)
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(cronschedulesv1.AddToScheme(scheme)).To(Succeed())
+
+		cr = &cronschedulesv1.CronJobScaleDown{
+			ObjectMeta: metav1.ObjectMeta{Name: "cleanup", Namespace: "default"},
+			Spec: cronschedulesv1.CronJobScaleDownSpec{
+				CleanupSchedule: "0 0 * * * *",
+				CleanupConfig: &cronschedulesv1.CleanupConfig{
+					AnnotationKey:      "cleanup-after",
+					ResourceTypes:      []string{"ConfigMap"},
+					MaxDeletionsPerRun: 2,
+				},
+			},
+		}
+		objs := []client.Object{cr}
+		for i := range 3 {
+			objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("cm-%d", i),
+				Namespace:   "default",
+				Annotations: map[string]string{"cleanup-after": ""},
+			}})
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		k8sClient = &utils.K8sClient{Client: fakeClient}
+
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &CronJobScaleDownReconciler{Client: fakeClient, Recorder: recorder}
+		now = time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+	})
+
+	It("should block a run exceeding maxDeletionsPerRun without deleting anything", func() {
+		updated, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeTrue())
+		Expect(configMapCount()).To(Equal(3))
+		Expect(cr.Status.LastCleanupTime.IsZero()).To(BeTrue())
+
+		condition := meta.FindStatusCondition(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("exceed maxDeletionsPerRun (2)"))
+		Expect(condition.Message).To(ContainSubstring(cronschedulesv1.AnnotationCleanupAcknowledged + "=3"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupBlocked")))
+
+		// Still blocked on the next reconcile, without another event
+		_, err = reconciler.executeCleanup(ctx, k8sClient, cr, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(3))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should block a run exceeding maxDeletionPercent", func() {
+		cr.Spec.CleanupConfig.MaxDeletionsPerRun = 0
+		cr.Spec.CleanupConfig.MaxDeletionPercent = 50
+
+		_, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(3))
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)).To(BeTrue())
+	})
+
+	It("should proceed once acknowledged and consume the acknowledgment", func() {
+		_, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupBlocked")))
+
+		cr.Annotations = map[string]string{cronschedulesv1.AnnotationCleanupAcknowledged: "3"}
+		Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+		updated, err := reconciler.executeCleanup(ctx, k8sClient, cr, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeTrue())
+		Expect(configMapCount()).To(BeZero())
+		Expect(cr.Status.LastCleanupResourceCount).To(Equal(int32(3)))
+		Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupAcknowledged")))
+
+		stored := &cronschedulesv1.CronJobScaleDown{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cr), stored)).To(Succeed())
+		Expect(stored.Annotations).NotTo(HaveKey(cronschedulesv1.AnnotationCleanupAcknowledged))
+		Expect(cr.ResourceVersion).To(Equal(stored.ResourceVersion))
+	})
+
+	It("should stay blocked when the acknowledgment does not match the planned deletions", func() {
+		cr.Annotations = map[string]string{cronschedulesv1.AnnotationCleanupAcknowledged: "2"}
+		Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+		_, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(3))
+
+		condition := meta.FindStatusCondition(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(`acknowledgment "2" does not match the 3 planned deletions`))
+		Expect(cr.Annotations).To(HaveKey(cronschedulesv1.AnnotationCleanupAcknowledged))
+	})
+
+	It("should keep an acknowledged paged run going until it plans more deletions than acknowledged", func() {
+		// ConfigMaps listed one per page, in name order, with the name of the next one as continuation token
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(cronschedulesv1.AddToScheme(scheme)).To(Succeed())
+		objs := []client.Object{cr}
+		for i, expired := range []bool{true, true, false, false, true, true} {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: "default"}}
+			if expired {
+				configMap.Annotations = map[string]string{"cleanup-after": ""}
+			}
+			objs = append(objs, configMap)
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		pagedClient := interceptor.NewClient(fakeClient, interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+				limit, token := listOpts.Limit, listOpts.Continue
+				listOpts.Limit, listOpts.Continue = 0, ""
+				if err := c.List(ctx, list, listOpts); err != nil || limit == 0 {
+					return err
+				}
+				items, err := meta.ExtractList(list)
+				Expect(err).NotTo(HaveOccurred())
+				slices.SortFunc(items, func(a, b runtime.Object) int {
+					return strings.Compare(a.(client.Object).GetName(), b.(client.Object).GetName())
+				})
+				start := slices.IndexFunc(items, func(item runtime.Object) bool { return item.(client.Object).GetName() >= token })
+				if start < 0 {
+					start = len(items)
+				}
+				end := min(start+int(limit), len(items))
+				Expect(meta.SetList(list, items[start:end])).To(Succeed())
+				if end < len(items) {
+					list.SetContinue(items[end].(client.Object).GetName())
+				}
+				return nil
+			},
+		})
+		k8sClient = &utils.K8sClient{Client: pagedClient}
+		reconciler = &CronJobScaleDownReconciler{Client: pagedClient, Recorder: recorder}
+		cr.Spec.CleanupConfig.MaxDeletionsPerRun = 1
+		cr.Spec.CleanupConfig.Paging = &cronschedulesv1.CleanupPaging{PageSize: 1, MaxPagesPerRun: 2}
+
+		// The first part plans 2 deletions and is blocked
+		_, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(6))
+		Expect(recorder.Events).To(Receive(ContainSubstring(cronschedulesv1.AnnotationCleanupAcknowledged + "=2")))
+
+		cr.Annotations = map[string]string{cronschedulesv1.AnnotationCleanupAcknowledged: "2"}
+		Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+		_, err = reconciler.executeCleanup(ctx, k8sClient, cr, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(4))
+		Expect(cr.Status.CleanupContinuation).NotTo(BeNil())
+		Expect(cr.Status.CleanupContinuation.Acknowledged).To(Equal(int32(2)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupAcknowledged")))
+
+		// The second part plans nothing more, so the run is still within the acknowledgment
+		_, err = reconciler.executeCleanup(ctx, k8sClient, cr, now.Add(2*time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)).To(BeTrue())
+		Expect(cr.Status.CleanupContinuation).NotTo(BeNil())
+		Expect(cr.Status.CleanupContinuation.Continue).To(Equal("cm-4"))
+		Expect(recorder.Events).NotTo(Receive())
+
+		// The third part takes the run to 4 planned deletions, above the acknowledgment
+		_, err = reconciler.executeCleanup(ctx, k8sClient, cr, now.Add(3*time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(Equal(4))
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(cronschedulesv1.AnnotationCleanupAcknowledged + "=4")))
+	})
+
+	It("should run normally within the limits", func() {
+		cr.Spec.CleanupConfig.MaxDeletionsPerRun = 3
+
+		_, err := reconciler.executeCleanup(ctx, k8sClient, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapCount()).To(BeZero())
+		Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, cronschedulesv1.ConditionTypeCleanupBlocked)).To(BeTrue())
+	})
+
+	It("should keep the deletion rate limiter of a CronJobScaleDown across reconciles", func() {
+		Expect(reconciler.deleteLimiter(cr)).To(BeNil())
+
+		cr.Spec.CleanupConfig.DeletesPerSecond = 5
+		limiter := reconciler.deleteLimiter(cr)
+		Expect(limiter).NotTo(BeNil())
+		Expect(limiter.Allow()).To(BeTrue())
+		Expect(reconciler.deleteLimiter(cr)).To(BeIdenticalTo(limiter))
+
+		// A new rate takes a new limiter
+		cr.Spec.CleanupConfig.DeletesPerSecond = 10
+		Expect(reconciler.deleteLimiter(cr)).NotTo(BeIdenticalTo(limiter))
+
+		cr.Spec.CleanupConfig.DeletesPerSecond = 0
+		Expect(reconciler.deleteLimiter(cr)).To(BeNil())
+		Expect(reconciler.deleteLimiters).To(BeEmpty())
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+func (r *CronJobScaleDownReconciler) validateCleanupPrecondition(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	precondition := cronJobScaleDown.Spec.CleanupPrecondition
+	if precondition == nil {
+		return nil
+	}
+	if !precondition.RequireTargetDown && !precondition.RequireNoRunningPods {
+		return fmt.Errorf("at least one of requireTargetDown and requireNoRunningPods must be set")
+	}
+	if cronJobScaleDown.Spec.TargetRef == nil {
+		return fmt.Errorf("targetRef is required by the cleanup precondition")
+	}
+	if precondition.RequireTargetDown {
+		if cronJobScaleDown.Spec.ScaleDownSchedule == "" {
+			return fmt.Errorf("requireTargetDown needs a scaleDownSchedule")
+		}
+		if scaleDownMode(cronJobScaleDown) != cronschedulesv1.ScaleDownModeReplicas {
+			return fmt.Errorf("requireTargetDown is not supported with scale down mode %s", scaleDownMode(cronJobScaleDown))
+		}
+	}
+	return nil
+}
+
+// checkCleanupPrecondition reports whether the cleanup run may proceed under the cleanup precondition.
+// A run that may not is recorded in the cleanup history with the reason, and counts as the run of its
+// schedule; a paged run it interrupts starts over at the next scheduled run. When the target can't be
+// checked, the error is returned and the run is retried without being recorded.
+func (r *CronJobScaleDownReconciler) checkCleanupPrecondition(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if cronJobScaleDown.Spec.CleanupPrecondition == nil {
+		return true, nil
+	}
+
+	reason, err := r.cleanupBlocker(ctx, k8sClient, cronJobScaleDown)
+	if err != nil {
+		return false, fmt.Errorf("failed to check cleanup precondition: %w", err)
+	}
+	if reason == "" {
+		return true, nil
+	}
+
+	recordSkippedCleanupRun(cronJobScaleDown, now, reason)
+	logger.Info("Cleanup skipped by cleanup precondition", "reason", reason)
+	r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "CleanupSkipped",
+		fmt.Sprintf("Cleanup skipped: %s", reason))
+	return false, nil
+}
+
+// cleanupBlocker returns why the scaling target isn't in the state the cleanup precondition requires, or an
+// empty string if it is
+func (r *CronJobScaleDownReconciler) cleanupBlocker(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) (string, error) {
+	precondition := cronJobScaleDown.Spec.CleanupPrecondition
+	target := utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}
+
+	if precondition.RequireTargetDown {
+		status := cronJobScaleDown.Status
+		if status.LastScaleDownTime.IsZero() {
+			return "target has not been scaled down yet", nil
+		}
+		if !status.LastScaleDownTime.After(status.LastScaleUpTime.Time) {
+			return fmt.Sprintf("target was scaled up at %s", status.LastScaleUpTime.Format(time.RFC3339)), nil
+		}
+		replicas := k8sClient.GetReplicasCount(ctx, target)
+		if replicas == nil {
+			return "", fmt.Errorf("failed to read replicas of %s %s", target.Kind, target.Name)
+		}
+		if *replicas > 0 {
+			return fmt.Sprintf("target has %d replica(s)", *replicas), nil
+		}
+	}
+
+	if precondition.RequireNoRunningPods {
+		running, err := k8sClient.ListRunningPods(ctx, target)
+		if err != nil {
+			return "", err
+		}
+		if len(running) > 0 {
+			names := running
+			if len(names) > maxListedBusyPods {
+				names = append(names[:maxListedBusyPods:maxListedBusyPods], "...")
+			}
+			return fmt.Sprintf("%d pod(s) still running: %s", len(running), strings.Join(names, ", ")), nil
+		}
+	}
+
+	return "", nil
+}
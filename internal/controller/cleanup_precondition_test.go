@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+var _ = Describe("Cleanup precondition", func() {
+	var (
+		reconciler *CronJobScaleDownReconciler
+		recorder   *record.FakeRecorder
+		deployment *appsv1.Deployment
+		cr         *cronschedulesv1.CronJobScaleDown
+		now        time.Time
+	)
+
+	newClient := func(objs ...runtime.Object) *utils.K8sClient {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		return &utils.K8sClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &CronJobScaleDownReconciler{Recorder: recorder}
+		now = time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](0),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			},
+		}
+		cr = &cronschedulesv1.CronJobScaleDown{
+			Spec: cronschedulesv1.CronJobScaleDownSpec{
+				TargetRef:         &cronschedulesv1.TargetRef{Name: "api", Namespace: "default", Kind: "Deployment"},
+				ScaleDownSchedule: "0 22 * * *",
+				ScaleUpSchedule:   "0 6 * * *",
+				CleanupSchedule:   "0 0 * * *",
+				CleanupConfig:     &cronschedulesv1.CleanupConfig{},
+				CleanupPrecondition: &cronschedulesv1.CleanupPrecondition{
+					RequireTargetDown:    true,
+					RequireNoRunningPods: true,
+				},
+			},
+			Status: cronschedulesv1.CronJobScaleDownStatus{
+				LastScaleUpTime:   metav1.NewTime(now.Add(-19 * time.Hour)),
+				LastScaleDownTime: metav1.NewTime(now.Add(-3 * time.Hour)),
+			},
+		}
+	})
+
+	It("should validate the precondition", func() {
+		Expect(reconciler.validateCleanupPrecondition(cr)).To(Succeed())
+
+		cr.Spec.CleanupPrecondition = &cronschedulesv1.CleanupPrecondition{}
+		Expect(reconciler.validateCleanupPrecondition(cr)).NotTo(Succeed())
+
+		cr.Spec.CleanupPrecondition = &cronschedulesv1.CleanupPrecondition{RequireTargetDown: true}
+		cr.Spec.ScaleDownMode = cronschedulesv1.ScaleDownModeRightsize
+		Expect(reconciler.validateCleanupPrecondition(cr)).NotTo(Succeed())
+
+		cr.Spec.TargetRef = nil
+		cr.Spec.CleanupPrecondition = &cronschedulesv1.CleanupPrecondition{RequireNoRunningPods: true}
+		Expect(reconciler.validateCleanupPrecondition(cr)).NotTo(Succeed())
+	})
+
+	It("should let cleanup run while the target is down", func() {
+		Expect(reconciler.checkCleanupPrecondition(ctx, newClient(deployment), cr, now)).To(BeTrue())
+		Expect(cr.Status.CleanupHistory).To(BeEmpty())
+	})
+
+	It("should skip cleanup once the target was scaled up", func() {
+		cr.Status.LastScaleUpTime = metav1.NewTime(now.Add(-time.Hour))
+		cr.Status.CleanupContinuation = &cronschedulesv1.CleanupContinuation{ResourceType: "ConfigMap", Namespace: "default"}
+
+		Expect(reconciler.checkCleanupPrecondition(ctx, newClient(deployment), cr, now)).To(BeFalse())
+		Expect(cr.Status.LastCleanupTime.Time).To(Equal(now))
+		Expect(cr.Status.CleanupContinuation).To(BeNil())
+		Expect(cr.Status.CleanupHistory).To(HaveLen(1))
+		Expect(cr.Status.CleanupHistory[0].SkippedReason).To(ContainSubstring("target was scaled up"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupSkipped")))
+	})
+
+	It("should skip cleanup while the target has replicas or running pods", func() {
+		deployment.Spec.Replicas = ptr.To[int32](2)
+		Expect(reconciler.checkCleanupPrecondition(ctx, newClient(deployment), cr, now)).To(BeFalse())
+		Expect(cr.Status.CleanupHistory[0].SkippedReason).To(Equal("target has 2 replica(s)"))
+
+		deployment.Spec.Replicas = ptr.To[int32](0)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default", Labels: map[string]string{"app": "api"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		Expect(reconciler.checkCleanupPrecondition(ctx, newClient(deployment, pod), cr, now)).To(BeFalse())
+		Expect(cr.Status.CleanupHistory[0].SkippedReason).To(Equal("1 pod(s) still running: api-0"))
+	})
+
+	It("should retry instead of skipping when the target can't be read", func() {
+		_, err := reconciler.checkCleanupPrecondition(ctx, newClient(), cr, now)
+		Expect(err).To(HaveOccurred())
+		Expect(cr.Status.LastCleanupTime.IsZero()).To(BeTrue())
+		Expect(cr.Status.CleanupHistory).To(BeEmpty())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
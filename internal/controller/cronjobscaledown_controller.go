@@ -21,13 +21,18 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/robfig/cron/v3"
+	"golang.org/x/time/rate"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -41,25 +46,54 @@ var (
 	validTimezonePattern = regexp.MustCompile(`^[A-Za-z]+(?:[_/][A-Za-z0-9_+-]+)*$`)
 	// Maximum schedule length to prevent extremely long schedules
 	maxScheduleLength = 100
+	// Number of cleanup run reports kept when historyLimit isn't set
+	defaultCleanupHistoryLimit = 5
+	// How soon a paged cleanup run paused until the next reconcile is resumed
+	cleanupContinuationInterval = 5 * time.Second
+	// How soon a cleanup run that failed before deleting anything, e.g. on a transient API error, is retried
+	cleanupRetryInterval = 30 * time.Second
+	// How often resources deleted with waitForDeletion are checked until they disappear
+	pendingDeletionCheckInterval = 5 * time.Second
+	// Number of upcoming cleanup runs checked for the longest interval between runs
+	scheduleIntervalSamples = 500
 )
 
 //+kubebuilder:rbac:groups=cronschedules.elbazi.co,resources=cronjobscaledowns,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cronschedules.elbazi.co,resources=cronjobscaledowns/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cronschedules.elbazi.co,resources=cronjobscaledowns/finalizers,verbs=update
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch;delete
-//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;delete
-//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;delete
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;delete
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
 
 // CronJobScaleDownReconciler reconciles a CronJobScaleDown object
 type CronJobScaleDownReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// ScaleLimiter bounds concurrent scale-ups and staggers scale events; nil disables it
+	ScaleLimiter *ScaleLimiter
+
+	// Recorder emits Events on CronJobScaleDown resources; nil disables them
+	Recorder record.EventRecorder
+
+	// APIReader serves cleanup lists with a field selector, which the cache can't; nil uses the client
+	APIReader client.Reader
+
+	// ArchiveSettings confine where cleanup archives are written
+	ArchiveSettings utils.ArchiveSettings
+
+	// deleteLimiters hold the deletion rate limiters of the CronJobScaleDowns with deletesPerSecond
+	deleteLimitersMu sync.Mutex
+	deleteLimiters   map[string]*rate.Limiter
+}
+
+// recordEvent emits an Event on the CronJobScaleDown when a recorder is configured
+func (r *CronJobScaleDownReconciler) recordEvent(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(cronJobScaleDown, eventType, reason, message)
 }
 
 func (r *CronJobScaleDownReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -69,6 +103,10 @@ func (r *CronJobScaleDownReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	cronJobScaleDown := &cronschedulesv1.CronJobScaleDown{}
 	if err := r.Get(ctx, req.NamespacedName, cronJobScaleDown); err != nil {
 		if client.IgnoreNotFound(err) == nil {
+			// Deleted: drop its deletion rate limiter
+			r.deleteLimitersMu.Lock()
+			delete(r.deleteLimiters, req.NamespacedName.String())
+			r.deleteLimitersMu.Unlock()
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "unable to fetch CronJobScaleDown")
@@ -80,6 +118,10 @@ func (r *CronJobScaleDownReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{RequeueAfter: time.Minute}, nil
 	}
 
+	if _, ok := cronJobScaleDown.Annotations[cronschedulesv1.AnnotationRestoreArchive]; ok {
+		r.restoreArchive(ctx, cronJobScaleDown)
+	}
+
 	return r.processSchedules(ctx, cronJobScaleDown)
 }
 
@@ -123,6 +165,15 @@ func (r *CronJobScaleDownReconciler) validateSpec(cronJobScaleDown *cronschedule
 		if err := r.validateCleanupConfig(cronJobScaleDown.Spec.CleanupConfig); err != nil {
 			return fmt.Errorf("invalid CleanupConfig: %w", err)
 		}
+		if err := r.validateCleanupPrecondition(cronJobScaleDown); err != nil {
+			return fmt.Errorf("invalid CleanupPrecondition: %w", err)
+		}
+		if err := r.validateArchiveNamespace(cronJobScaleDown); err != nil {
+			return fmt.Errorf("invalid CleanupConfig: %w", err)
+		}
+		if err := r.validateWarnBefore(cronJobScaleDown); err != nil {
+			return fmt.Errorf("invalid CleanupConfig: %w", err)
+		}
 	}
 
 	// Validate timezone
@@ -133,6 +184,18 @@ func (r *CronJobScaleDownReconciler) validateSpec(cronJobScaleDown *cronschedule
 		return fmt.Errorf("invalid TimeZone: %w", err)
 	}
 
+	if err := r.validateHooks(cronJobScaleDown.Spec.Hooks); err != nil {
+		return fmt.Errorf("invalid Hooks: %w", err)
+	}
+
+	if err := r.validateDrainCondition(cronJobScaleDown.Spec.DrainCondition); err != nil {
+		return fmt.Errorf("invalid DrainCondition: %w", err)
+	}
+
+	if err := r.validateScaleDownMode(cronJobScaleDown); err != nil {
+		return fmt.Errorf("invalid ScaleDownMode: %w", err)
+	}
+
 	// Validate target reference only if scaling schedules are provided
 	if cronJobScaleDown.Spec.ScaleDownSchedule != "" || cronJobScaleDown.Spec.ScaleUpSchedule != "" {
 		if cronJobScaleDown.Spec.TargetRef == nil {
@@ -168,6 +231,45 @@ func (r *CronJobScaleDownReconciler) validateCronSchedule(schedule string) error
 	return nil
 }
 
+// validateWarnBefore checks that warnBefore covers the longest interval between cleanup runs. Notices are
+// only given by cleanup runs, so a shorter window would let resources expire between two runs unannounced.
+func (r *CronJobScaleDownReconciler) validateWarnBefore(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	cleanupConfig := cronJobScaleDown.Spec.CleanupConfig
+	if cleanupConfig.WarnBefore == "" {
+		return nil
+	}
+	warnBefore, err := utils.ParseDuration(cleanupConfig.WarnBefore)
+	if err != nil {
+		return fmt.Errorf("invalid warnBefore format: %w", err)
+	}
+
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(cronJobScaleDown.Spec.CleanupSchedule)
+	if err != nil {
+		return fmt.Errorf("invalid CleanupSchedule: %w", err)
+	}
+	if interval := longestScheduleInterval(schedule); warnBefore < interval {
+		return fmt.Errorf("warnBefore (%s) is shorter than the longest interval between cleanup runs (%s)", cleanupConfig.WarnBefore, interval)
+	}
+	return nil
+}
+
+// longestScheduleInterval returns the longest interval between runs of the schedule over the next
+// scheduleIntervalSamples runs
+func longestScheduleInterval(schedule cron.Schedule) time.Duration {
+	var longest time.Duration
+	previous := schedule.Next(time.Now())
+	for range scheduleIntervalSamples {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		longest = max(longest, next.Sub(previous))
+		previous = next
+	}
+	return longest
+}
+
 func (r *CronJobScaleDownReconciler) validateTimezone(timezone string) error {
 	// Sanitize timezone
 	timezone = strings.TrimSpace(timezone)
@@ -232,25 +334,69 @@ func (r *CronJobScaleDownReconciler) validateCleanupConfig(cleanupConfig *cronsc
 		return fmt.Errorf("at least one resource type must be specified for cleanup")
 	}
 
-	// Validate supported resource types
-	supportedTypes := map[string]bool{
-		"Deployment":         true,
-		"StatefulSet":        true,
-		"Service":            true,
-		"ConfigMap":          true,
-		"Secret":             true,
-		"Role":               true,
-		"RoleBinding":        true,
-		"ClusterRole":        true,
-		"ClusterRoleBinding": true,
-	}
-
+	// Validate supported resource types; kinds outside the built-in ones are checked against the cluster at cleanup time
 	for _, resourceType := range cleanupConfig.ResourceTypes {
-		if !supportedTypes[resourceType] {
+		if !utils.IsSupportedResourceType(resourceType) {
 			return fmt.Errorf("unsupported resource type for cleanup: %s", resourceType)
 		}
 	}
 
+	for _, phase := range cleanupConfig.PodPhases {
+		switch phase {
+		case string(corev1.PodFailed), string(corev1.PodSucceeded), cronschedulesv1.PodPhaseEvicted:
+		default:
+			return fmt.Errorf("unsupported pod phase filter: %s", phase)
+		}
+	}
+	for _, condition := range cleanupConfig.JobConditions {
+		switch condition {
+		case string(batchv1.JobComplete), string(batchv1.JobFailed):
+		default:
+			return fmt.Errorf("unsupported job condition filter: %s", condition)
+		}
+	}
+	if _, err := utils.ParseResourceFilter(cleanupConfig); err != nil {
+		return err
+	}
+	if err := validateCleanupArchive(cleanupConfig); err != nil {
+		return err
+	}
+
+	if cleanupConfig.WarnBefore != "" {
+		if _, err := utils.ParseDuration(cleanupConfig.WarnBefore); err != nil {
+			return fmt.Errorf("invalid warnBefore format: %w", err)
+		}
+		if resourceType := utils.UnpatchableResourceType(cleanupConfig.ResourceTypes); resourceType != "" {
+			return fmt.Errorf("warnBefore can't be used with %s, which the operator does not patch", resourceType)
+		}
+	}
+	if cleanupConfig.QuarantinePeriod != "" {
+		if _, err := utils.ParseDuration(cleanupConfig.QuarantinePeriod); err != nil {
+			return fmt.Errorf("invalid quarantinePeriod format: %w", err)
+		}
+		if resourceType := utils.UnpatchableResourceType(cleanupConfig.ResourceTypes); resourceType != "" {
+			return fmt.Errorf("quarantinePeriod can't be used with %s, which the operator does not patch", resourceType)
+		}
+	}
+	if cleanupConfig.RescuePeriod != "" {
+		if _, err := utils.ParseDuration(cleanupConfig.RescuePeriod); err != nil {
+			return fmt.Errorf("invalid rescuePeriod format: %w", err)
+		}
+	}
+	if cleanupConfig.DeletionTimeout != "" {
+		if _, err := utils.ParseDuration(cleanupConfig.DeletionTimeout); err != nil {
+			return fmt.Errorf("invalid deletionTimeout format: %w", err)
+		}
+	}
+	if cleanupConfig.GracePeriodSeconds != nil && *cleanupConfig.GracePeriodSeconds < 0 {
+		return fmt.Errorf("gracePeriodSeconds cannot be negative")
+	}
+	switch metav1.DeletionPropagation(cleanupConfig.PropagationPolicy) {
+	case "", metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+	default:
+		return fmt.Errorf("unsupported propagationPolicy: %s", cleanupConfig.PropagationPolicy)
+	}
+
 	// Validate orphan cleanup configuration
 	if cleanupConfig.CleanupOrphanResources {
 		if cleanupConfig.OrphanResourceMaxAge == "" {
@@ -258,9 +404,69 @@ func (r *CronJobScaleDownReconciler) validateCleanupConfig(cleanupConfig *cronsc
 		}
 
 		// Validate the duration format
-		if _, err := time.ParseDuration(cleanupConfig.OrphanResourceMaxAge); err != nil {
+		if _, err := utils.ParseDuration(cleanupConfig.OrphanResourceMaxAge); err != nil {
 			return fmt.Errorf("invalid orphanResourceMaxAge format: %w", err)
 		}
+
+		switch cleanupConfig.OrphanDetection {
+		case "", cronschedulesv1.OrphanDetectionAge, cronschedulesv1.OrphanDetectionOwnerReferences, cronschedulesv1.OrphanDetectionUnreferenced:
+		default:
+			return fmt.Errorf("unsupported orphanDetection mode: %s", cleanupConfig.OrphanDetection)
+		}
+	}
+
+	switch cleanupConfig.AgeSource {
+	case "", cronschedulesv1.AgeSourceCreation, cronschedulesv1.AgeSourceLastUpdate, cronschedulesv1.AgeSourceLastRollout, cronschedulesv1.AgeSourceLastPodStart:
+	default:
+		return fmt.Errorf("unsupported ageSource: %s", cleanupConfig.AgeSource)
+	}
+	switch cleanupConfig.DateDeadline {
+	case "", cronschedulesv1.DateDeadlineStartOfDay, cronschedulesv1.DateDeadlineEndOfDay:
+	default:
+		return fmt.Errorf("unsupported dateDeadline: %s", cleanupConfig.DateDeadline)
+	}
+
+	if selector := cleanupConfig.NamespaceSelector; selector != nil && selector.NameRegex != "" {
+		if _, err := regexp.Compile(selector.NameRegex); err != nil {
+			return fmt.Errorf("invalid namespaceSelector.nameRegex: %w", err)
+		}
+	}
+	if deletion := cleanupConfig.DeleteNamespace; deletion != nil {
+		if cleanupConfig.NamespaceSelector == nil && len(cleanupConfig.Namespaces) == 0 {
+			return fmt.Errorf("deleteNamespace requires namespaces or namespaceSelector")
+		}
+		if cleanupConfig.Archive != nil {
+			return fmt.Errorf("deleteNamespace can't be combined with archive: the namespaces' contents would not be archived")
+		}
+		if deletion.IdlePeriod != "" {
+			if _, err := utils.ParseDuration(deletion.IdlePeriod); err != nil {
+				return fmt.Errorf("invalid deleteNamespace.idlePeriod format: %w", err)
+			}
+		}
+	}
+
+	if unreferenced := cleanupConfig.UnreferencedConfigs; unreferenced != nil {
+		if len(unreferenced.Kinds) == 0 {
+			return fmt.Errorf("unreferencedConfigs.kinds must list the kinds to clean up")
+		}
+		for _, kind := range unreferenced.Kinds {
+			if kind != "ConfigMap" && kind != "Secret" {
+				return fmt.Errorf("unsupported kind for unreferencedConfigs: %s", kind)
+			}
+		}
+		if unreferenced.GraceAge != "" {
+			if _, err := utils.ParseDuration(unreferenced.GraceAge); err != nil {
+				return fmt.Errorf("invalid unreferencedConfigs.graceAge format: %w", err)
+			}
+		}
+	}
+
+	if cascade := cleanupConfig.Cascade; cascade != nil {
+		for _, kind := range cascade.Kinds {
+			if !utils.IsCascadeKind(kind) {
+				return fmt.Errorf("unsupported kind for cascade: %s", kind)
+			}
+		}
 	}
 
 	return nil
@@ -268,22 +474,28 @@ func (r *CronJobScaleDownReconciler) validateCleanupConfig(cleanupConfig *cronsc
 
 func (r *CronJobScaleDownReconciler) processSchedules(ctx context.Context, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	k8sClient := &utils.K8sClient{Client: r.Client}
+	k8sClient := &utils.K8sClient{Client: r.Client, Recorder: r.Recorder, APIReader: r.APIReader, ArchiveSettings: r.ArchiveSettings}
 
 	location, err := time.LoadLocation(cronJobScaleDown.Spec.TimeZone)
 	if err != nil {
 		logger.Error(err, "Error loading timezone", "timezone", cronJobScaleDown.Spec.TimeZone)
 		return ctrl.Result{}, nil
 	}
+	k8sClient.Location = location
+	k8sClient.DeleteLimiter = r.deleteLimiter(cronJobScaleDown)
 	now := time.Now().In(location)
 
-	scaleDownNext, err := r.parseSchedule(cronJobScaleDown.Spec.ScaleDownSchedule, now)
+	// Scale events are shifted by the resource's stagger offset so that resources
+	// sharing a schedule don't all fire at the same second
+	stagger := r.ScaleLimiter.StaggerDelay(limiterKey(cronJobScaleDown))
+
+	scaleDownNext, err := r.parseStaggeredSchedule(cronJobScaleDown.Spec.ScaleDownSchedule, now, stagger)
 	if err != nil {
 		logger.Error(err, "Error parsing scale down schedule", "schedule", cronJobScaleDown.Spec.ScaleDownSchedule)
 		return ctrl.Result{}, nil
 	}
 
-	scaleUpNext, err := r.parseSchedule(cronJobScaleDown.Spec.ScaleUpSchedule, now)
+	scaleUpNext, err := r.parseStaggeredSchedule(cronJobScaleDown.Spec.ScaleUpSchedule, now, stagger)
 	if err != nil {
 		logger.Error(err, "Error parsing scale up schedule", "schedule", cronJobScaleDown.Spec.ScaleUpSchedule)
 		return ctrl.Result{}, nil
@@ -295,25 +507,61 @@ func (r *CronJobScaleDownReconciler) processSchedules(ctx context.Context, cronJ
 		return ctrl.Result{}, nil
 	}
 
-	didScale, err := r.executeScaling(ctx, k8sClient, cronJobScaleDown, now, scaleDownNext, scaleUpNext)
+	scalingUpdated, err := r.executeScaling(ctx, k8sClient, cronJobScaleDown, now, scaleDownNext, scaleUpNext)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	didCleanup, err := r.executeCleanup(ctx, k8sClient, cronJobScaleDown, now)
-	if err != nil {
-		logger.Error(err, "Error executing cleanup")
-		// Don't return error, just log it and continue
+	deletionsUpdated := r.checkPendingDeletions(ctx, k8sClient, cronJobScaleDown, now)
+
+	didCleanup, cleanupErr := r.executeCleanup(ctx, k8sClient, cronJobScaleDown, now)
+	if cleanupErr != nil {
+		// Don't return error, just log it and continue; the run is retried after cleanupRetryInterval
+		logger.Error(cleanupErr, "Error executing cleanup")
 	}
 
-	if didScale || didCleanup {
+	if scalingUpdated || deletionsUpdated || didCleanup {
 		if err := r.Status().Update(ctx, cronJobScaleDown); err != nil {
 			logger.Error(err, "Error updating CronJobScaleDown status")
 			return ctrl.Result{}, err
 		}
 	}
 
-	return r.calculateRequeue(logger, now, scaleDownNext, scaleUpNext, cleanupNext), nil
+	result := r.calculateRequeue(logger, now, scaleDownNext, scaleUpNext, cleanupNext)
+	if cleanupErr != nil {
+		result = requeueWithin(result, cleanupRetryInterval)
+	}
+	if r.ScaleLimiter.Pending(limiterKey(cronJobScaleDown)) {
+		result = requeueWithin(result, scaleLimiterRetryInterval)
+	}
+	if hasRunningHooks(cronJobScaleDown) {
+		result = requeueWithin(result, hookPollInterval)
+	}
+	if cronJobScaleDown.Status.Drain != nil && cronJobScaleDown.Spec.DrainCondition != nil {
+		result = requeueWithin(result, drainCheckInterval(cronJobScaleDown.Spec.DrainCondition))
+	}
+	if cronJobScaleDown.Status.CleanupContinuation != nil {
+		// A run paused by deletesPerSecond resumes as soon as the rate allows another deletion
+		delay := cleanupContinuationInterval
+		if k8sClient.DeleteLimiter != nil {
+			if d := utils.DeletionDelay(k8sClient.DeleteLimiter); d > 0 {
+				delay = d
+			}
+		}
+		result = requeueWithin(result, delay)
+	}
+	if len(cronJobScaleDown.Status.PendingDeletions) > 0 {
+		result = requeueWithin(result, pendingDeletionCheckInterval)
+	}
+	return result, nil
+}
+
+// requeueWithin makes sure the result requeues no later than after d
+func requeueWithin(result ctrl.Result, d time.Duration) ctrl.Result {
+	if result.RequeueAfter == 0 || result.RequeueAfter > d {
+		result.RequeueAfter = d
+	}
+	return result
 }
 
 func (r *CronJobScaleDownReconciler) parseSchedule(schedule string, now time.Time) (time.Time, error) {
@@ -328,6 +576,15 @@ func (r *CronJobScaleDownReconciler) parseSchedule(schedule string, now time.Tim
 	return cronSchedule.Next(now), nil
 }
 
+// parseStaggeredSchedule returns the next activation of schedule after now, delayed by stagger
+func (r *CronJobScaleDownReconciler) parseStaggeredSchedule(schedule string, now time.Time, stagger time.Duration) (time.Time, error) {
+	next, err := r.parseSchedule(schedule, now.Add(-stagger))
+	if err != nil || next.IsZero() {
+		return next, err
+	}
+	return next.Add(stagger), nil
+}
+
 func (r *CronJobScaleDownReconciler) shouldExecuteNow(schedule string, now time.Time, lastExecutionTime time.Time) bool {
 	if schedule == "" {
 		return false
@@ -348,7 +605,6 @@ func (r *CronJobScaleDownReconciler) shouldExecuteNow(schedule string, now time.
 
 func (r *CronJobScaleDownReconciler) executeScaling(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time, scaleDownNext, scaleUpNext time.Time) (bool, error) {
 	logger := log.FromContext(ctx)
-	var didScale bool
 
 	// Skip scaling if no targetRef is provided
 	if cronJobScaleDown.Spec.TargetRef == nil {
@@ -363,61 +619,199 @@ func (r *CronJobScaleDownReconciler) executeScaling(ctx context.Context, k8sClie
 		"lastScaleDownTime", cronJobScaleDown.Status.LastScaleDownTime.Time.Format(time.RFC3339),
 		"lastScaleUpTime", cronJobScaleDown.Status.LastScaleUpTime.Time.Format(time.RFC3339))
 
+	updated := r.pollRunningHooks(ctx, k8sClient, cronJobScaleDown, now)
+
 	if r.shouldScaleDown(cronJobScaleDown, now) {
-		logger.Info("Scaling down the target resource")
-		if err := k8sClient.ScaleDownTargetResource(ctx, utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}); err != nil {
-			if apierrors.IsNotFound(err) {
-				logger.Info("Target resource not found for scale down, skipping", "error", err.Error())
-			} else {
-				logger.Error(err, "Error scaling down target resource")
-				return false, err
-			}
-		} else {
-			cronJobScaleDown.Status.LastScaleDownTime = metav1.Time{Time: now}
-			r.updateCurrentReplicas(ctx, k8sClient, cronJobScaleDown)
-			didScale = true
+		scaled, err := r.scaleDown(ctx, k8sClient, cronJobScaleDown, now)
+		if err != nil {
+			return false, err
 		}
+		updated = updated || scaled
 	}
 
 	if r.shouldScaleUp(cronJobScaleDown, now) {
-		logger.Info("Scaling up the target resource")
-		if err := k8sClient.ScaleUpTargetResource(ctx, utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}); err != nil {
-			if apierrors.IsNotFound(err) {
-				logger.Info("Target resource not found for scale up, skipping", "error", err.Error())
-			} else {
-				logger.Error(err, "Error scaling up target resource")
-				return false, err
-			}
-		} else {
-			cronJobScaleDown.Status.LastScaleUpTime = metav1.Time{Time: now}
-			r.updateCurrentReplicas(ctx, k8sClient, cronJobScaleDown)
-			didScale = true
+		scaled, err := r.scaleUp(ctx, k8sClient, cronJobScaleDown, now)
+		if err != nil {
+			return false, err
+		}
+		updated = updated || scaled
+	} else if r.ScaleLimiter.Holds(limiterKey(cronJobScaleDown)) {
+		r.releaseScaleUpSlotIfReady(ctx, k8sClient, cronJobScaleDown)
+	}
+
+	return updated, nil
+}
+
+// scaleDown runs the scale down event with its hooks. It returns true if the status changed.
+func (r *CronJobScaleDownReconciler) scaleDown(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) (bool, error) {
+	logger := log.FromContext(ctx)
+	scheduled := r.scheduledTime(cronJobScaleDown.Spec.ScaleDownSchedule, lastScaleDownHandled(cronJobScaleDown))
+
+	outcome, updated := r.runPreHook(ctx, k8sClient, cronJobScaleDown, cronschedulesv1.HookPhasePreScaleDown, scheduled, now)
+	switch outcome {
+	case hookPending:
+		logger.Info("Scale down waiting for preScaleDown hook")
+		return updated, nil
+	case hookAbort:
+		logger.Info("Scale down cancelled by preScaleDown hook")
+		cronJobScaleDown.Status.LastAbortedScaleDownTime = metav1.Time{Time: now}
+		return true, nil
+	}
+
+	drained, drainUpdated := r.checkDrain(ctx, k8sClient, cronJobScaleDown, scheduled, now)
+	updated = updated || drainUpdated
+	if !drained {
+		return updated, nil
+	}
+
+	logger.Info("Scaling down the target resource", "mode", scaleDownMode(cronJobScaleDown))
+	if err := r.applyScaleDown(ctx, k8sClient, cronJobScaleDown); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Target resource not found for scale down, skipping", "error", err.Error())
+			return updated, nil
 		}
+		logger.Error(err, "Error scaling down target resource")
+		return false, err
 	}
 
-	return didScale, nil
+	cronJobScaleDown.Status.LastScaleDownTime = metav1.Time{Time: now}
+	r.updateCurrentReplicas(ctx, k8sClient, cronJobScaleDown)
+	r.runPostHook(ctx, k8sClient, cronJobScaleDown, cronschedulesv1.HookPhasePostScaleDown, scheduled, now)
+	return true, nil
+}
+
+// scaleUp runs the scale up event with its hooks, subject to the scale limiter. It returns true if the status changed.
+func (r *CronJobScaleDownReconciler) scaleUp(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) (bool, error) {
+	logger := log.FromContext(ctx)
+	scheduled := r.scheduledTime(cronJobScaleDown.Spec.ScaleUpSchedule, lastScaleUpHandled(cronJobScaleDown))
+
+	outcome, updated := r.runPreHook(ctx, k8sClient, cronJobScaleDown, cronschedulesv1.HookPhasePreScaleUp, scheduled, now)
+	switch outcome {
+	case hookPending:
+		logger.Info("Scale up waiting for preScaleUp hook")
+		return updated, nil
+	case hookAbort:
+		logger.Info("Scale up cancelled by preScaleUp hook")
+		cronJobScaleDown.Status.LastAbortedScaleUpTime = metav1.Time{Time: now}
+		return true, nil
+	}
+
+	key := limiterKey(cronJobScaleDown)
+	if !r.ScaleLimiter.Acquire(key, cronJobScaleDown.Spec.Priority) {
+		logger.Info("Scale up deferred, concurrent scale-up limit reached",
+			"priority", cronJobScaleDown.Spec.Priority,
+			"queueDepth", r.ScaleLimiter.QueueDepth())
+		return updated, nil
+	}
+
+	logger.Info("Scaling up the target resource", "mode", scaleDownMode(cronJobScaleDown))
+	if err := r.applyScaleUp(ctx, k8sClient, cronJobScaleDown); err != nil {
+		r.ScaleLimiter.Release(key)
+		if apierrors.IsNotFound(err) {
+			logger.Info("Target resource not found for scale up, skipping", "error", err.Error())
+			return updated, nil
+		}
+		logger.Error(err, "Error scaling up target resource")
+		return false, err
+	}
+
+	cronJobScaleDown.Status.LastScaleUpTime = metav1.Time{Time: now}
+	r.updateCurrentReplicas(ctx, k8sClient, cronJobScaleDown)
+	r.runPostHook(ctx, k8sClient, cronJobScaleDown, cronschedulesv1.HookPhasePostScaleUp, scheduled, now)
+	return true, nil
+}
+
+// releaseScaleUpSlotIfReady gives back the scale-up slot once the target has finished rolling out
+func (r *CronJobScaleDownReconciler) releaseScaleUpSlotIfReady(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) {
+	logger := log.FromContext(ctx)
+
+	ready, err := k8sClient.IsTargetReady(ctx, utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Error checking target readiness, keeping scale-up slot")
+		return
+	}
+	if ready || apierrors.IsNotFound(err) {
+		logger.Info("Target ready after scale up, releasing scale-up slot")
+		r.ScaleLimiter.Release(limiterKey(cronJobScaleDown))
+	}
 }
 
 func (r *CronJobScaleDownReconciler) shouldScaleDown(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) bool {
 	return r.shouldExecuteNow(
 		cronJobScaleDown.Spec.ScaleDownSchedule,
-		now,
-		cronJobScaleDown.Status.LastScaleDownTime.Time,
+		now.Add(-r.ScaleLimiter.StaggerDelay(limiterKey(cronJobScaleDown))),
+		lastScaleDownHandled(cronJobScaleDown),
 	)
 }
 
 func (r *CronJobScaleDownReconciler) shouldScaleUp(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) bool {
 	return r.shouldExecuteNow(
 		cronJobScaleDown.Spec.ScaleUpSchedule,
-		now,
-		cronJobScaleDown.Status.LastScaleUpTime.Time,
+		now.Add(-r.ScaleLimiter.StaggerDelay(limiterKey(cronJobScaleDown))),
+		lastScaleUpHandled(cronJobScaleDown),
 	)
 }
 
+// scheduledTime returns the schedule time of the scale event following lastHandled
+func (r *CronJobScaleDownReconciler) scheduledTime(schedule string, lastHandled time.Time) time.Time {
+	next, err := r.parseSchedule(schedule, lastHandled)
+	if err != nil {
+		return time.Time{}
+	}
+	return next
+}
+
+// lastScaleDownHandled returns when the last scale down event was performed or cancelled by a hook
+func lastScaleDownHandled(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) time.Time {
+	return latest(cronJobScaleDown.Status.LastScaleDownTime.Time, cronJobScaleDown.Status.LastAbortedScaleDownTime.Time)
+}
+
+// lastScaleUpHandled returns when the last scale up event was performed or cancelled by a hook
+func lastScaleUpHandled(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) time.Time {
+	return latest(cronJobScaleDown.Status.LastScaleUpTime.Time, cronJobScaleDown.Status.LastAbortedScaleUpTime.Time)
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// limiterKey identifies a CronJobScaleDown in the scale limiter
+func limiterKey(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) string {
+	return cronJobScaleDown.Namespace + "/" + cronJobScaleDown.Name
+}
+
+// deleteLimiter returns the deletion rate limiter of the CronJobScaleDown, kept across reconciles so that
+// deletesPerSecond holds across the parts of a cleanup run, or nil when deletesPerSecond isn't set
+func (r *CronJobScaleDownReconciler) deleteLimiter(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) *rate.Limiter {
+	key := limiterKey(cronJobScaleDown)
+	r.deleteLimitersMu.Lock()
+	defer r.deleteLimitersMu.Unlock()
+
+	cleanupConfig := cronJobScaleDown.Spec.CleanupConfig
+	if cleanupConfig == nil || cleanupConfig.DeletesPerSecond <= 0 {
+		delete(r.deleteLimiters, key)
+		return nil
+	}
+	limiter := r.deleteLimiters[key]
+	if limiter == nil || limiter.Limit() != rate.Limit(cleanupConfig.DeletesPerSecond) {
+		limiter = utils.NewDeleteLimiter(cleanupConfig.DeletesPerSecond)
+		if r.deleteLimiters == nil {
+			r.deleteLimiters = make(map[string]*rate.Limiter)
+		}
+		r.deleteLimiters[key] = limiter
+	}
+	return limiter
+}
+
 func (r *CronJobScaleDownReconciler) executeCleanup(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	if !r.shouldCleanup(cronJobScaleDown, now) {
+	// A paged run paused until the next reconcile is resumed whatever the schedule
+	resume := cronJobScaleDown.Status.CleanupContinuation
+	if resume == nil && !r.shouldCleanup(cronJobScaleDown, now) {
 		return false, nil
 	}
 
@@ -426,23 +820,115 @@ func (r *CronJobScaleDownReconciler) executeCleanup(ctx context.Context, k8sClie
 		return false, fmt.Errorf("cleanup config is nil")
 	}
 
+	// A run finding the target in another state than the cleanup precondition requires is skipped
+	proceed, err := r.checkCleanupPrecondition(ctx, k8sClient, cronJobScaleDown, now)
+	if err != nil {
+		return false, err
+	}
+	if !proceed {
+		return true, nil
+	}
+
 	logger.Info("Executing resource cleanup")
 
 	// Use the CronJobScaleDown's namespace as default
 	defaultNamespace := cronJobScaleDown.Namespace
-	cleanedCount, err := k8sClient.CleanupResources(ctx, cronJobScaleDown.Spec.CleanupConfig, defaultNamespace)
+
+	namespaces, err := k8sClient.ResolveNamespaces(ctx, cronJobScaleDown.Spec.CleanupConfig, defaultNamespace)
+	if err != nil {
+		logger.Error(err, "Failed to resolve cleanup namespaces")
+		return false, err
+	}
+	if err := k8sClient.ValidateCleanupResourceTypes(ctx, cronJobScaleDown.Spec.CleanupConfig.ResourceTypes, namespaces,
+		utils.CleanupVerbs(cronJobScaleDown.Spec.CleanupConfig)); err != nil {
+		logger.Error(err, "Cleanup resource types failed validation")
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "InvalidCleanupConfig", err.Error())
+		return false, err
+	}
+	// A field selector some kind can't serve is reported in the cleanup history instead of failing every list
+	if err := utils.ValidateFieldSelector(cronJobScaleDown.Spec.CleanupConfig); err != nil {
+		logger.Error(err, "Cleanup field selector failed validation")
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "InvalidCleanupConfig", err.Error())
+		recordSkippedCleanupRun(cronJobScaleDown, now, err.Error())
+		return true, nil
+	}
+
+	plan, err := k8sClient.PlanCleanup(ctx, cronJobScaleDown.Spec.CleanupConfig, defaultNamespace, resume)
 	if err != nil {
 		logger.Error(err, "Error during resource cleanup")
 		return false, err
 	}
 
-	cronJobScaleDown.Status.LastCleanupTime = metav1.Time{Time: now}
-	cronJobScaleDown.Status.LastCleanupResourceCount = cleanedCount
+	// A run exceeding the deletion limits waits for an acknowledgment; LastCleanupTime is left
+	// untouched so that it is retried on every reconcile until then
+	proceed, acknowledged := r.checkCleanupLimits(cronJobScaleDown, plan)
+	if !proceed {
+		return true, nil
+	}
+
+	// Nothing is deleted unless it could be archived first
+	archive, err := r.archiveCleanup(ctx, k8sClient, cronJobScaleDown, plan, now)
+	if err != nil {
+		return false, err
+	}
 
-	logger.Info("Cleanup completed", "resourcesCleaned", cleanedCount)
+	result := k8sClient.ExecuteCleanupPlan(ctx, plan, cronJobScaleDown.Spec.CleanupConfig)
+	if acknowledged {
+		if err := r.consumeCleanupAcknowledgment(ctx, cronJobScaleDown); err != nil {
+			logger.Error(err, "Failed to remove cleanup acknowledgment annotation")
+		}
+	}
+
+	cronJobScaleDown.Status.LastCleanupTime = metav1.Time{Time: now}
+	cronJobScaleDown.Status.LastCleanupResourceCount = result.Deleted
+	cronJobScaleDown.Status.LastCleanupSkipped = result.Skipped
+	cronJobScaleDown.Status.LastCleanupArchive = archive
+	cronJobScaleDown.Status.QuarantinedResources = result.QuarantinedResources
+	cronJobScaleDown.Status.UpcomingDeletions = result.UpcomingDeletions
+	cronJobScaleDown.Status.CleanupContinuation = plan.Continuation
+	for _, pending := range result.PendingDeletions {
+		pending.RunTime = metav1.Time{Time: now}
+		cronJobScaleDown.Status.PendingDeletions = append(cronJobScaleDown.Status.PendingDeletions, pending)
+	}
+	recordCleanupRun(cronJobScaleDown, result.Report(now, cronJobScaleDown.Spec.CleanupConfig.DryRun))
+	if result.Failed > 0 {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "CleanupFailed",
+			fmt.Sprintf("Failed to clean up %d resources, see status.cleanupHistory", result.Failed))
+	}
+	if result.Quarantined > 0 {
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "ResourcesQuarantined",
+			fmt.Sprintf("Quarantined %d resources for %s before deletion", result.Quarantined, cronJobScaleDown.Spec.CleanupConfig.QuarantinePeriod))
+	}
+
+	logger.Info("Cleanup completed", "resourcesCleaned", result.Deleted, "resourcesQuarantined", result.Quarantined,
+		"resourcesFailed", result.Failed, "resourcesSkipped", len(result.Skipped), "continues", plan.Continuation != nil)
 	return true, nil
 }
 
+// recordSkippedCleanupRun records a run that was skipped before planning, which consumes the schedule slot
+func recordSkippedCleanupRun(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time, reason string) {
+	cronJobScaleDown.Status.LastCleanupTime = metav1.Time{Time: now}
+	cronJobScaleDown.Status.CleanupContinuation = nil
+	recordCleanupRun(cronJobScaleDown, cronschedulesv1.CleanupRun{
+		Time:          metav1.Time{Time: now},
+		DryRun:        cronJobScaleDown.Spec.CleanupConfig.DryRun,
+		SkippedReason: reason,
+	})
+}
+
+// recordCleanupRun adds the report of a cleanup run to the history, keeping the latest historyLimit runs
+func recordCleanupRun(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, run cronschedulesv1.CleanupRun) {
+	limit := int(cronJobScaleDown.Spec.CleanupConfig.HistoryLimit)
+	if limit <= 0 {
+		limit = defaultCleanupHistoryLimit
+	}
+	history := append([]cronschedulesv1.CleanupRun{run}, cronJobScaleDown.Status.CleanupHistory...)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	cronJobScaleDown.Status.CleanupHistory = history
+}
+
 func (r *CronJobScaleDownReconciler) shouldCleanup(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) bool {
 	return r.shouldExecuteNow(
 		cronJobScaleDown.Spec.CleanupSchedule,
@@ -491,6 +977,7 @@ func (r *CronJobScaleDownReconciler) calculateRequeue(logger logr.Logger, now ti
 func (r *CronJobScaleDownReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cronschedulesv1.CronJobScaleDown{}).
+		Owns(&batchv1.Job{}).
 		Named("cronjobscaledown").
 		Complete(r)
 }
@@ -279,3 +279,20 @@ var _ = Describe("CronJobScaleDown Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Advance warnings", func() {
+	It("should require warnBefore to cover the longest interval between cleanup runs", func() {
+		reconciler := &CronJobScaleDownReconciler{}
+		cr := &cronschedulesv1.CronJobScaleDown{Spec: cronschedulesv1.CronJobScaleDownSpec{
+			CleanupSchedule: "0 0 0 * * 1",
+			CleanupConfig:   &cronschedulesv1.CleanupConfig{WarnBefore: "1d"},
+		}}
+		Expect(reconciler.validateWarnBefore(cr)).To(MatchError(ContainSubstring("shorter than the longest interval")))
+
+		cr.Spec.CleanupConfig.WarnBefore = "7d"
+		Expect(reconciler.validateWarnBefore(cr)).To(Succeed())
+
+		cr.Spec.CleanupSchedule = "0 0 0 1 * *"
+		Expect(reconciler.validateWarnBefore(cr)).NotTo(Succeed())
+	})
+})
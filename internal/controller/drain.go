@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+const (
+	// defaultDrainMaxDelay applies when a drain condition doesn't set maxDelay
+	defaultDrainMaxDelay = time.Hour
+	// defaultDrainCheckInterval applies when a drain condition doesn't set checkInterval
+	defaultDrainCheckInterval = time.Minute
+	// maxListedBusyPods bounds the pod names included in a drain reason
+	maxListedBusyPods = 5
+)
+
+func (r *CronJobScaleDownReconciler) validateDrainCondition(drain *cronschedulesv1.DrainCondition) error {
+	if drain == nil {
+		return nil
+	}
+	if len(drain.JobSelector) == 0 && drain.PodIdleAnnotation == "" && drain.PodIdleCondition == "" {
+		return fmt.Errorf("at least one of jobSelector, podIdleAnnotation and podIdleCondition must be set")
+	}
+	if drain.MaxDelay != "" {
+		if _, err := utils.ParseDuration(drain.MaxDelay); err != nil {
+			return fmt.Errorf("invalid maxDelay: %w", err)
+		}
+	}
+	if drain.CheckInterval != "" {
+		interval, err := utils.ParseDuration(drain.CheckInterval)
+		if err != nil {
+			return fmt.Errorf("invalid checkInterval: %w", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("checkInterval must be positive")
+		}
+	}
+	return nil
+}
+
+// checkDrain reports whether the scale down scheduled at scheduled may proceed under the drain condition,
+// and whether the status was changed. Scale down proceeds anyway once maxDelay has passed.
+func (r *CronJobScaleDownReconciler) checkDrain(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, scheduled, now time.Time) (bool, bool) {
+	logger := log.FromContext(ctx)
+
+	drain := cronJobScaleDown.Spec.DrainCondition
+	if drain == nil {
+		return true, false
+	}
+
+	status := cronJobScaleDown.Status.Drain
+	if status == nil || !status.ScheduledTime.Time.Equal(scheduled) {
+		status = &cronschedulesv1.DrainStatus{
+			ScheduledTime: metav1.Time{Time: scheduled},
+			Since:         metav1.Time{Time: now},
+		}
+		cronJobScaleDown.Status.Drain = status
+	} else if now.Sub(status.LastCheckTime.Time) < drainCheckInterval(drain) {
+		return false, false
+	}
+	status.LastCheckTime = metav1.Time{Time: now}
+
+	reason, err := r.drainBlocker(ctx, k8sClient, cronJobScaleDown)
+	if err != nil {
+		logger.Error(err, "Failed to check drain condition")
+		reason = fmt.Sprintf("failed to check drain condition: %v", err)
+	}
+
+	if reason == "" {
+		logger.Info("Drain condition met", "waited", now.Sub(status.Since.Time).Round(time.Second))
+		cronJobScaleDown.Status.Drain = nil
+		return true, true
+	}
+
+	maxDelay := durationOrDefault(drain.MaxDelay, defaultDrainMaxDelay)
+	if now.Sub(status.Since.Time) >= maxDelay {
+		logger.Info("Drain condition not met within maxDelay, scaling down anyway", "maxDelay", maxDelay, "reason", reason)
+		r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "DrainTimeout",
+			fmt.Sprintf("Scaling down after waiting %s for the drain condition: %s", maxDelay, reason))
+		cronJobScaleDown.Status.Drain = nil
+		return true, true
+	}
+
+	status.Postponements++
+	status.Reason = reason
+	logger.Info("Scale down deferred by drain condition", "reason", reason, "postponements", status.Postponements)
+	r.recordEvent(cronJobScaleDown, corev1.EventTypeNormal, "ScaleDownDeferred",
+		fmt.Sprintf("Scale down deferred: %s", reason))
+	return false, true
+}
+
+// drainBlocker returns why the target isn't drained yet, or an empty string if it is
+func (r *CronJobScaleDownReconciler) drainBlocker(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) (string, error) {
+	drain := cronJobScaleDown.Spec.DrainCondition
+	target := utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}
+
+	if len(drain.JobSelector) > 0 {
+		running, err := k8sClient.CountRunningJobs(ctx, target.Namespace, drain.JobSelector)
+		if err != nil {
+			return "", err
+		}
+		if running > 0 {
+			return fmt.Sprintf("%d job(s) still running", running), nil
+		}
+	}
+
+	if drain.PodIdleAnnotation != "" || drain.PodIdleCondition != "" {
+		busy, err := k8sClient.ListBusyPods(ctx, target, drain.PodIdleAnnotation, drain.PodIdleCondition)
+		if err != nil {
+			return "", err
+		}
+		if len(busy) > 0 {
+			names := busy
+			if len(names) > maxListedBusyPods {
+				names = append(names[:maxListedBusyPods:maxListedBusyPods], "...")
+			}
+			return fmt.Sprintf("%d pod(s) not idle: %s", len(busy), strings.Join(names, ", ")), nil
+		}
+	}
+
+	return "", nil
+}
+
+func drainCheckInterval(drain *cronschedulesv1.DrainCondition) time.Duration {
+	return durationOrDefault(drain.CheckInterval, defaultDrainCheckInterval)
+}
+
+func durationOrDefault(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := utils.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
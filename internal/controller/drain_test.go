@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+var _ = Describe("Drain condition", func() {
+	var (
+		reconciler *CronJobScaleDownReconciler
+		recorder   *record.FakeRecorder
+		k8sClient  *utils.K8sClient
+		cr         *cronschedulesv1.CronJobScaleDown
+		scheduled  time.Time
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+		k8sClient = &utils.K8sClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default", Labels: map[string]string{"app": "batch"}}},
+		).Build()}
+
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &CronJobScaleDownReconciler{Recorder: recorder}
+		scheduled = time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+		cr = &cronschedulesv1.CronJobScaleDown{
+			Spec: cronschedulesv1.CronJobScaleDownSpec{
+				TargetRef: &cronschedulesv1.TargetRef{Name: "worker", Namespace: "default", Kind: "Deployment"},
+				DrainCondition: &cronschedulesv1.DrainCondition{
+					JobSelector:   map[string]string{"app": "batch"},
+					MaxDelay:      "30m",
+					CheckInterval: "1m",
+				},
+			},
+		}
+	})
+
+	It("should require at least one check", func() {
+		Expect(reconciler.validateDrainCondition(&cronschedulesv1.DrainCondition{MaxDelay: "1h"})).NotTo(Succeed())
+		Expect(reconciler.validateDrainCondition(cr.Spec.DrainCondition)).To(Succeed())
+	})
+
+	It("should defer scale down while jobs run and give up after maxDelay", func() {
+		drained, updated := reconciler.checkDrain(ctx, k8sClient, cr, scheduled, scheduled)
+		Expect(drained).To(BeFalse())
+		Expect(updated).To(BeTrue())
+		Expect(cr.Status.Drain.Postponements).To(Equal(int32(1)))
+		Expect(cr.Status.Drain.Reason).To(ContainSubstring("1 job(s) still running"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ScaleDownDeferred")))
+
+		drained, updated = reconciler.checkDrain(ctx, k8sClient, cr, scheduled, scheduled.Add(30*time.Second))
+		Expect(drained).To(BeFalse())
+		Expect(updated).To(BeFalse())
+
+		drained, _ = reconciler.checkDrain(ctx, k8sClient, cr, scheduled, scheduled.Add(31*time.Minute))
+		Expect(drained).To(BeTrue())
+		Expect(cr.Status.Drain).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("DrainTimeout")))
+	})
+})
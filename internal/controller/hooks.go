@@ -0,0 +1,392 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+const (
+	// hookPollInterval is how often running hooks are checked
+	hookPollInterval = 10 * time.Second
+	// defaultHookTimeout applies when a hook doesn't set a timeout
+	defaultHookTimeout = 10 * time.Minute
+	// defaultHookRequestTimeout applies to HTTP hook requests when requestTimeout isn't set
+	defaultHookRequestTimeout = 10 * time.Second
+	// hookRetryBackoff is the delay before the first retry of an HTTP hook; it doubles on every attempt
+	hookRetryBackoff = 10 * time.Second
+)
+
+// hookOutcome tells the caller of a pre-scale hook how to continue with the scale event
+type hookOutcome int
+
+const (
+	hookProceed hookOutcome = iota
+	hookPending
+	hookAbort
+)
+
+func (r *CronJobScaleDownReconciler) validateHooks(hooks *cronschedulesv1.ScaleHooks) error {
+	if hooks == nil {
+		return nil
+	}
+
+	for _, phase := range []string{
+		cronschedulesv1.HookPhasePreScaleDown,
+		cronschedulesv1.HookPhasePostScaleDown,
+		cronschedulesv1.HookPhasePreScaleUp,
+		cronschedulesv1.HookPhasePostScaleUp,
+	} {
+		hook := hookFor(hooks, phase)
+		if hook == nil {
+			continue
+		}
+		if (hook.JobTemplate == nil) == (hook.HTTP == nil) {
+			return fmt.Errorf("%s hook must define exactly one of jobTemplate and http", phase)
+		}
+		if hook.JobTemplate != nil && len(hook.JobTemplate.Spec.Template.Spec.Containers) == 0 {
+			return fmt.Errorf("%s hook must define a job template with at least one container", phase)
+		}
+		if hook.HTTP != nil {
+			if err := validateHTTPHook(hook.HTTP); err != nil {
+				return fmt.Errorf("invalid %s hook: %w", phase, err)
+			}
+		}
+		if hook.Timeout != "" {
+			if _, err := utils.ParseDuration(hook.Timeout); err != nil {
+				return fmt.Errorf("invalid %s hook timeout: %w", phase, err)
+			}
+		}
+		switch hook.FailurePolicy {
+		case "", cronschedulesv1.HookFailurePolicyAbort, cronschedulesv1.HookFailurePolicyContinue:
+		default:
+			return fmt.Errorf("unsupported %s hook failure policy: %s", phase, hook.FailurePolicy)
+		}
+	}
+
+	return nil
+}
+
+func validateHTTPHook(hook *cronschedulesv1.HTTPHook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if hook.RequestTimeout != "" {
+		if _, err := utils.ParseDuration(hook.RequestTimeout); err != nil {
+			return fmt.Errorf("invalid requestTimeout: %w", err)
+		}
+	}
+	if hook.Retries < 0 {
+		return fmt.Errorf("retries cannot be negative")
+	}
+	if hook.SignatureSecretRef != nil && (hook.SignatureSecretRef.Name == "" || hook.SignatureSecretRef.Key == "") {
+		return fmt.Errorf("signatureSecretRef requires a name and a key")
+	}
+	return nil
+}
+
+// runPreHook starts or checks the hook of the given phase for the scale event scheduled at scheduled.
+// It returns whether the scale event may proceed and whether the status was changed.
+func (r *CronJobScaleDownReconciler) runPreHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, phase string, scheduled, now time.Time) (hookOutcome, bool) {
+	logger := log.FromContext(ctx)
+
+	hook := hookFor(cronJobScaleDown.Spec.Hooks, phase)
+	if hook == nil {
+		return hookProceed, false
+	}
+
+	updated := false
+	status := findHookStatus(cronJobScaleDown, phase)
+	if status == nil || !status.ScheduledTime.Time.Equal(scheduled) {
+		r.startHook(ctx, k8sClient, cronJobScaleDown, phase, scheduled, now)
+		status = findHookStatus(cronJobScaleDown, phase)
+		updated = true
+	}
+
+	switch status.Result {
+	case cronschedulesv1.HookResultRunning:
+		return hookPending, updated
+	case cronschedulesv1.HookResultSucceeded:
+		return hookProceed, updated
+	default:
+		if hook.FailurePolicy == cronschedulesv1.HookFailurePolicyContinue {
+			logger.Info("Hook did not succeed, continuing as configured", "phase", phase, "result", status.Result)
+			return hookProceed, updated
+		}
+		return hookAbort, updated
+	}
+}
+
+// runPostHook starts the hook of the given phase after a scale event, if one is configured
+func (r *CronJobScaleDownReconciler) runPostHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, phase string, scheduled, now time.Time) {
+	if hookFor(cronJobScaleDown.Spec.Hooks, phase) == nil {
+		return
+	}
+	r.startHook(ctx, k8sClient, cronJobScaleDown, phase, scheduled, now)
+}
+
+// startHook runs the hook of the given phase. HTTP hooks make their first request right away and are
+// recorded as running while a failed request waits for its retry; hook Jobs are recorded as running, or
+// as failed if the Job can't be created.
+func (r *CronJobScaleDownReconciler) startHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, phase string, scheduled, now time.Time) {
+	logger := log.FromContext(ctx)
+	hook := hookFor(cronJobScaleDown.Spec.Hooks, phase)
+
+	status := cronschedulesv1.HookStatus{
+		Phase:         phase,
+		Result:        cronschedulesv1.HookResultRunning,
+		ScheduledTime: metav1.Time{Time: scheduled},
+		StartTime:     metav1.Time{Time: now},
+	}
+
+	if hook.HTTP != nil {
+		r.attemptHTTPHook(ctx, k8sClient, cronJobScaleDown, hook, &status, now)
+		setHookStatus(cronJobScaleDown, status)
+		return
+	}
+
+	jobName, err := k8sClient.CreateHookJob(ctx, cronJobScaleDown, phase, hook, hookTimeout(hook), now)
+	if err != nil {
+		logger.Error(err, "Failed to start hook", "phase", phase)
+		finishHookStatus(&status, cronschedulesv1.HookResultFailed, fmt.Sprintf("failed to create hook job: %v", err), now)
+	} else {
+		status.JobName = jobName
+	}
+
+	setHookStatus(cronJobScaleDown, status)
+}
+
+// attemptHTTPHook makes one request of the HTTP hook and records its outcome. A retryable failure leaves
+// the hook running until its next attempt, which pollRunningHooks makes on a later reconcile, after a
+// backoff doubling on every attempt.
+func (r *CronJobScaleDownReconciler) attemptHTTPHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, hook *cronschedulesv1.ScaleHook, status *cronschedulesv1.HookStatus, now time.Time) {
+	logger := log.FromContext(ctx)
+
+	status.Attempts++
+	status.NextAttemptTime = nil
+	retryable, err := r.callHTTPHook(ctx, k8sClient, cronJobScaleDown, hook, status.Phase, status.ScheduledTime.Time)
+	if err == nil {
+		finishHookStatus(status, cronschedulesv1.HookResultSucceeded, fmt.Sprintf("%s accepted the event", hook.HTTP.URL), time.Now())
+		return
+	}
+	if !retryable || status.Attempts > hook.HTTP.Retries {
+		logger.Error(err, "HTTP hook failed", "phase", status.Phase, "url", hook.HTTP.URL, "attempts", status.Attempts)
+		finishHookStatus(status, cronschedulesv1.HookResultFailed, err.Error(), time.Now())
+		return
+	}
+
+	next := now.Add(hookRetryBackoff << (status.Attempts - 1))
+	logger.Info("HTTP hook failed, retrying", "phase", status.Phase, "url", hook.HTTP.URL, "attempt", status.Attempts, "retryAt", next, "error", err.Error())
+	status.NextAttemptTime = &metav1.Time{Time: next}
+	status.Message = fmt.Sprintf("attempt %d failed, retrying: %v", status.Attempts, err)
+}
+
+// callHTTPHook POSTs the scale event to the hook's URL once, reporting whether a failure may be retried
+func (r *CronJobScaleDownReconciler) callHTTPHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, hook *cronschedulesv1.ScaleHook, phase string, scheduled time.Time) (bool, error) {
+	var signingKey []byte
+	if ref := hook.HTTP.SignatureSecretRef; ref != nil {
+		key, err := k8sClient.GetSecretKey(ctx, cronJobScaleDown.Namespace, ref)
+		if err != nil {
+			return true, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		signingKey = key
+	}
+
+	target := utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}
+	event := utils.HookEvent{
+		CronJobScaleDown: utils.HookEventObject{
+			Name:      cronJobScaleDown.Name,
+			Namespace: cronJobScaleDown.Namespace,
+		},
+		Target: utils.HookEventObject{
+			APIVersion: target.ApiVersion,
+			Kind:       target.Kind,
+			Name:       target.Name,
+			Namespace:  target.Namespace,
+		},
+		Direction:     hookDirection(phase),
+		Phase:         phase,
+		Replicas:      k8sClient.GetReplicasCount(ctx, target),
+		ScheduledTime: scheduled,
+		Timestamp:     time.Now(),
+	}
+
+	requestTimeout := defaultHookRequestTimeout
+	if hook.HTTP.RequestTimeout != "" {
+		if d, err := utils.ParseDuration(hook.HTTP.RequestTimeout); err == nil {
+			requestTimeout = d
+		}
+	}
+
+	return utils.PostHookEvent(ctx, hook.HTTP.URL, event, signingKey, requestTimeout)
+}
+
+// hookDirection returns the scale direction ("down" or "up") of a hook phase
+func hookDirection(phase string) string {
+	switch phase {
+	case cronschedulesv1.HookPhasePreScaleUp, cronschedulesv1.HookPhasePostScaleUp:
+		return "up"
+	}
+	return "down"
+}
+
+// pollRunningHooks refreshes the status of hooks still running and enforces their timeouts.
+// It returns true if any hook status changed.
+func (r *CronJobScaleDownReconciler) pollRunningHooks(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) bool {
+	logger := log.FromContext(ctx)
+	if cronJobScaleDown.Spec.TargetRef == nil {
+		return false
+	}
+	namespace := cronJobScaleDown.Namespace
+
+	updated := false
+	for i := range cronJobScaleDown.Status.Hooks {
+		status := &cronJobScaleDown.Status.Hooks[i]
+		if status.Result != cronschedulesv1.HookResultRunning {
+			continue
+		}
+
+		if hook := hookFor(cronJobScaleDown.Spec.Hooks, status.Phase); hook != nil && hook.HTTP != nil {
+			if r.pollHTTPHook(ctx, k8sClient, cronJobScaleDown, hook, status, now) {
+				updated = true
+			}
+			continue
+		}
+
+		result, err := k8sClient.GetHookJobResult(ctx, namespace, status.JobName)
+		if err != nil {
+			logger.Error(err, "Failed to get hook job status", "phase", status.Phase, "job", status.JobName)
+			continue
+		}
+
+		switch result.Result {
+		case cronschedulesv1.HookResultRunning:
+			timeout := hookTimeout(hookFor(cronJobScaleDown.Spec.Hooks, status.Phase))
+			if now.Sub(status.StartTime.Time) <= timeout {
+				continue
+			}
+			logger.Info("Hook timed out", "phase", status.Phase, "job", status.JobName, "timeout", timeout)
+			if err := k8sClient.DeleteHookJob(ctx, namespace, status.JobName); err != nil {
+				logger.Error(err, "Failed to delete timed out hook job", "job", status.JobName)
+			}
+			finishHookStatus(status, cronschedulesv1.HookResultTimedOut, fmt.Sprintf("hook did not finish within %s", timeout), now)
+		default:
+			completion := now
+			if result.CompletionTime != nil {
+				completion = *result.CompletionTime
+			}
+			logger.Info("Hook finished", "phase", status.Phase, "job", status.JobName, "result", result.Result)
+			finishHookStatus(status, result.Result, result.Message, completion)
+		}
+		updated = true
+	}
+
+	return updated
+}
+
+// pollHTTPHook retries a running HTTP hook once its next attempt is due, and fails it once the hook
+// timeout has passed. It returns true if the hook status changed.
+func (r *CronJobScaleDownReconciler) pollHTTPHook(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, hook *cronschedulesv1.ScaleHook, status *cronschedulesv1.HookStatus, now time.Time) bool {
+	if timeout := hookTimeout(hook); now.Sub(status.StartTime.Time) > timeout {
+		log.FromContext(ctx).Info("HTTP hook timed out", "phase", status.Phase, "url", hook.HTTP.URL, "attempts", status.Attempts)
+		finishHookStatus(status, cronschedulesv1.HookResultTimedOut,
+			fmt.Sprintf("hook did not succeed within %s: %s", timeout, status.Message), now)
+		status.NextAttemptTime = nil
+		return true
+	}
+	if status.NextAttemptTime != nil && now.Before(status.NextAttemptTime.Time) {
+		return false
+	}
+	r.attemptHTTPHook(ctx, k8sClient, cronJobScaleDown, hook, status, now)
+	return true
+}
+
+// hasRunningHooks reports whether any hook is still running
+func hasRunningHooks(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) bool {
+	for _, status := range cronJobScaleDown.Status.Hooks {
+		if status.Result == cronschedulesv1.HookResultRunning {
+			return true
+		}
+	}
+	return false
+}
+
+func hookFor(hooks *cronschedulesv1.ScaleHooks, phase string) *cronschedulesv1.ScaleHook {
+	if hooks == nil {
+		return nil
+	}
+	switch phase {
+	case cronschedulesv1.HookPhasePreScaleDown:
+		return hooks.PreScaleDown
+	case cronschedulesv1.HookPhasePostScaleDown:
+		return hooks.PostScaleDown
+	case cronschedulesv1.HookPhasePreScaleUp:
+		return hooks.PreScaleUp
+	case cronschedulesv1.HookPhasePostScaleUp:
+		return hooks.PostScaleUp
+	}
+	return nil
+}
+
+func hookTimeout(hook *cronschedulesv1.ScaleHook) time.Duration {
+	if hook == nil || hook.Timeout == "" {
+		return defaultHookTimeout
+	}
+	timeout, err := utils.ParseDuration(hook.Timeout)
+	if err != nil {
+		return defaultHookTimeout
+	}
+	return timeout
+}
+
+func findHookStatus(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, phase string) *cronschedulesv1.HookStatus {
+	for i := range cronJobScaleDown.Status.Hooks {
+		if cronJobScaleDown.Status.Hooks[i].Phase == phase {
+			return &cronJobScaleDown.Status.Hooks[i]
+		}
+	}
+	return nil
+}
+
+// setHookStatus replaces the recorded run of the hook's phase
+func setHookStatus(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, status cronschedulesv1.HookStatus) {
+	if existing := findHookStatus(cronJobScaleDown, status.Phase); existing != nil {
+		*existing = status
+		return
+	}
+	cronJobScaleDown.Status.Hooks = append(cronJobScaleDown.Status.Hooks, status)
+}
+
+func finishHookStatus(status *cronschedulesv1.HookStatus, result, message string, completion time.Time) {
+	status.Result = result
+	status.Message = message
+	status.CompletionTime = &metav1.Time{Time: completion}
+	status.Duration = completion.Sub(status.StartTime.Time).Round(time.Second).String()
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+var _ = Describe("Scale hooks", func() {
+	var reconciler *CronJobScaleDownReconciler
+
+	newHook := func() *cronschedulesv1.ScaleHook {
+		return &cronschedulesv1.ScaleHook{
+			JobTemplate: &batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "hook", Image: "busybox"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		reconciler = &CronJobScaleDownReconciler{}
+	})
+
+	It("should validate hook definitions", func() {
+		Expect(reconciler.validateHooks(nil)).To(Succeed())
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleDown: newHook()})).To(Succeed())
+
+		empty := &cronschedulesv1.ScaleHook{JobTemplate: &batchv1.JobTemplateSpec{}}
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PostScaleUp: empty})).NotTo(Succeed())
+
+		badTimeout := newHook()
+		badTimeout.Timeout = "soon"
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleUp: badTimeout})).NotTo(Succeed())
+
+		badPolicy := newHook()
+		badPolicy.FailurePolicy = "Retry"
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleUp: badPolicy})).NotTo(Succeed())
+
+		httpHook := &cronschedulesv1.ScaleHook{HTTP: &cronschedulesv1.HTTPHook{URL: "https://api.example.com/prepare-shutdown"}}
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleDown: httpHook})).To(Succeed())
+
+		both := newHook()
+		both.HTTP = httpHook.HTTP
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleDown: both})).NotTo(Succeed())
+
+		relative := &cronschedulesv1.ScaleHook{HTTP: &cronschedulesv1.HTTPHook{URL: "/prepare-shutdown"}}
+		Expect(reconciler.validateHooks(&cronschedulesv1.ScaleHooks{PreScaleDown: relative})).NotTo(Succeed())
+	})
+
+	It("should resolve a finished pre-scale hook according to its failure policy", func() {
+		scheduled := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+		hook := newHook()
+		cr := &cronschedulesv1.CronJobScaleDown{
+			Spec: cronschedulesv1.CronJobScaleDownSpec{
+				Hooks: &cronschedulesv1.ScaleHooks{PreScaleDown: hook},
+			},
+			Status: cronschedulesv1.CronJobScaleDownStatus{
+				Hooks: []cronschedulesv1.HookStatus{{
+					Phase:         cronschedulesv1.HookPhasePreScaleDown,
+					Result:        cronschedulesv1.HookResultFailed,
+					ScheduledTime: metav1.Time{Time: scheduled},
+				}},
+			},
+		}
+
+		outcome, updated := reconciler.runPreHook(ctx, nil, cr, cronschedulesv1.HookPhasePreScaleDown, scheduled, scheduled)
+		Expect(outcome).To(Equal(hookAbort))
+		Expect(updated).To(BeFalse())
+
+		hook.FailurePolicy = cronschedulesv1.HookFailurePolicyContinue
+		outcome, _ = reconciler.runPreHook(ctx, nil, cr, cronschedulesv1.HookPhasePreScaleDown, scheduled, scheduled)
+		Expect(outcome).To(Equal(hookProceed))
+
+		cr.Status.Hooks[0].Result = cronschedulesv1.HookResultRunning
+		Expect(hasRunningHooks(cr)).To(BeTrue())
+		outcome, _ = reconciler.runPreHook(ctx, nil, cr, cronschedulesv1.HookPhasePreScaleDown, scheduled, scheduled)
+		Expect(outcome).To(Equal(hookPending))
+	})
+
+	It("should retry a failing HTTP hook on later reconciles", func() {
+		statuses := []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(statuses[requests])
+			requests++
+		}))
+		defer server.Close()
+
+		ctx := context.Background()
+		scheduled := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+		k8sClient := &utils.K8sClient{Client: fake.NewClientBuilder().Build()}
+		cr := &cronschedulesv1.CronJobScaleDown{
+			Spec: cronschedulesv1.CronJobScaleDownSpec{
+				TargetRef: &cronschedulesv1.TargetRef{Name: "api", Namespace: "default", Kind: "Deployment"},
+				Hooks: &cronschedulesv1.ScaleHooks{PreScaleDown: &cronschedulesv1.ScaleHook{
+					HTTP: &cronschedulesv1.HTTPHook{URL: server.URL, Retries: 2},
+				}},
+			},
+		}
+
+		outcome, updated := reconciler.runPreHook(ctx, k8sClient, cr, cronschedulesv1.HookPhasePreScaleDown, scheduled, scheduled)
+		Expect(outcome).To(Equal(hookPending))
+		Expect(updated).To(BeTrue())
+		status := findHookStatus(cr, cronschedulesv1.HookPhasePreScaleDown)
+		Expect(status.Attempts).To(Equal(int32(1)))
+		Expect(status.NextAttemptTime.Time).To(Equal(scheduled.Add(hookRetryBackoff)))
+
+		// Not due yet
+		Expect(reconciler.pollRunningHooks(ctx, k8sClient, cr, scheduled.Add(time.Second))).To(BeFalse())
+		Expect(requests).To(Equal(1))
+
+		Expect(reconciler.pollRunningHooks(ctx, k8sClient, cr, scheduled.Add(hookRetryBackoff))).To(BeTrue())
+		Expect(status.Attempts).To(Equal(int32(2)))
+		Expect(status.NextAttemptTime.Time).To(Equal(scheduled.Add(3 * hookRetryBackoff)))
+
+		Expect(reconciler.pollRunningHooks(ctx, k8sClient, cr, scheduled.Add(3*hookRetryBackoff))).To(BeTrue())
+		Expect(status.Result).To(Equal(cronschedulesv1.HookResultSucceeded))
+		Expect(status.Attempts).To(Equal(int32(3)))
+	})
+})
@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// scaleUpQueueDepth is the number of resources waiting for a scale-up slot
+	scaleUpQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cronjobscaledown_scale_up_queue_depth",
+		Help: "Number of CronJobScaleDown resources waiting for a free scale-up slot",
+	})
+
+	// scaleUpsInFlight is the number of scale-ups currently holding a slot
+	scaleUpsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cronjobscaledown_scale_ups_in_flight",
+		Help: "Number of scale-ups started and not yet reported ready",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(scaleUpQueueDepth, scaleUpsInFlight)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+// checkPendingDeletions checks the resources deleted with waitForDeletion that hadn't disappeared yet. The
+// report of the run that deleted a resource counts it as deleted once it is gone, or as failed once its
+// deadline passes. It returns true if the status changed.
+func (r *CronJobScaleDownReconciler) checkPendingDeletions(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown, now time.Time) bool {
+	logger := log.FromContext(ctx)
+
+	pendingDeletions := cronJobScaleDown.Status.PendingDeletions
+	if len(pendingDeletions) == 0 {
+		return false
+	}
+
+	var remaining []cronschedulesv1.PendingDeletion
+	for _, pending := range pendingDeletions {
+		exists, err := k8sClient.DeletionPending(ctx, utils.PendingDeletionObject(pending))
+		if err != nil {
+			logger.Error(err, "Failed to check pending deletion", "kind", pending.Kind, "name", pending.Name, "namespace", pending.Namespace)
+			exists = true
+		}
+		switch {
+		case !exists:
+			settlePendingDeletion(cronJobScaleDown, pending, cronschedulesv1.CleanupActionDeleted, "")
+		case !now.Before(pending.Deadline.Time):
+			message := fmt.Sprintf("resource was not deleted within %s", pending.Deadline.Sub(pending.RunTime.Time).Round(time.Second))
+			logger.Info("Resource was not deleted in time", "kind", pending.Kind, "name", pending.Name, "namespace", pending.Namespace)
+			settlePendingDeletion(cronJobScaleDown, pending, cronschedulesv1.CleanupActionFailed, message)
+			r.recordEvent(cronJobScaleDown, corev1.EventTypeWarning, "CleanupFailed",
+				fmt.Sprintf("%s %s/%s: %s", pending.Kind, pending.Namespace, pending.Name, message))
+		default:
+			remaining = append(remaining, pending)
+		}
+	}
+	cronJobScaleDown.Status.PendingDeletions = remaining
+	return len(remaining) != len(pendingDeletions)
+}
+
+// settlePendingDeletion records the outcome of a pending deletion in the report of the run that deleted it
+func settlePendingDeletion(cronJobScaleDown *cronschedulesv1.CronJobScaleDown, pending cronschedulesv1.PendingDeletion, action, message string) {
+	status := &cronJobScaleDown.Status
+	for i := range status.CleanupHistory {
+		run := &status.CleanupHistory[i]
+		if !run.Time.Equal(&pending.RunTime) {
+			continue
+		}
+		if action == cronschedulesv1.CleanupActionDeleted {
+			run.Deleted++
+			if i == 0 {
+				status.LastCleanupResourceCount++
+			}
+		} else {
+			run.Failed++
+		}
+		for j := range run.Resources {
+			record := &run.Resources[j]
+			if record.Action == cronschedulesv1.CleanupActionDeleting && record.Kind == pending.Kind &&
+				record.Namespace == pending.Namespace && record.Name == pending.Name {
+				record.Action = action
+				record.Message = message
+				break
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+var _ = Describe("Pending deletions", func() {
+	It("should settle pending deletions in the report of their run", func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		k8sClient := &utils.K8sClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default", UID: "stuck-uid"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "recreated", Namespace: "default", UID: "new-uid"}},
+		).Build()}
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &CronJobScaleDownReconciler{Recorder: recorder}
+
+		runTime := metav1.NewTime(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC))
+		deadline := metav1.NewTime(runTime.Add(2 * time.Minute))
+		pending := func(name, uid string) cronschedulesv1.PendingDeletion {
+			return cronschedulesv1.PendingDeletion{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: name,
+				UID: types.UID(uid), RunTime: runTime, Deadline: deadline}
+		}
+		deleting := func(name string) cronschedulesv1.CleanupRecord {
+			return cronschedulesv1.CleanupRecord{Kind: "ConfigMap", Namespace: "default", Name: name, Action: cronschedulesv1.CleanupActionDeleting}
+		}
+		cr := &cronschedulesv1.CronJobScaleDown{
+			Status: cronschedulesv1.CronJobScaleDownStatus{
+				PendingDeletions: []cronschedulesv1.PendingDeletion{
+					pending("gone", "gone-uid"), pending("recreated", "old-uid"), pending("stuck", "stuck-uid"),
+				},
+				CleanupHistory: []cronschedulesv1.CleanupRun{{
+					Time:      runTime,
+					Resources: []cronschedulesv1.CleanupRecord{deleting("gone"), deleting("recreated"), deleting("stuck")},
+				}},
+			},
+		}
+
+		Expect(reconciler.checkPendingDeletions(ctx, k8sClient, cr, runTime.Add(time.Minute))).To(BeTrue())
+		run := cr.Status.CleanupHistory[0]
+		Expect(run.Deleted).To(Equal(int32(2)))
+		Expect(cr.Status.LastCleanupResourceCount).To(Equal(int32(2)))
+		Expect(run.Resources[0].Action).To(Equal(cronschedulesv1.CleanupActionDeleted))
+		Expect(run.Resources[1].Action).To(Equal(cronschedulesv1.CleanupActionDeleted))
+		Expect(cr.Status.PendingDeletions).To(HaveLen(1))
+
+		Expect(reconciler.checkPendingDeletions(ctx, k8sClient, cr, runTime.Add(90*time.Second))).To(BeFalse())
+
+		Expect(reconciler.checkPendingDeletions(ctx, k8sClient, cr, deadline.Time)).To(BeTrue())
+		run = cr.Status.CleanupHistory[0]
+		Expect(run.Failed).To(Equal(int32(1)))
+		Expect(run.Resources[2].Action).To(Equal(cronschedulesv1.CleanupActionFailed))
+		Expect(run.Resources[2].Message).To(ContainSubstring("not deleted within 2m0s"))
+		Expect(cr.Status.PendingDeletions).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(ContainSubstring("CleanupFailed")))
+	})
+})
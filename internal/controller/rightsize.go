@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
+)
+
+func (r *CronJobScaleDownReconciler) validateScaleDownMode(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	switch scaleDownMode(cronJobScaleDown) {
+	case cronschedulesv1.ScaleDownModeReplicas:
+		return nil
+	case cronschedulesv1.ScaleDownModeRightsize:
+	default:
+		return fmt.Errorf("unsupported scale down mode: %s", cronJobScaleDown.Spec.ScaleDownMode)
+	}
+
+	profile := cronJobScaleDown.Spec.RightsizeProfile
+	if profile == nil || len(profile.Containers) == 0 {
+		return fmt.Errorf("rightsizeProfile with at least one container is required in Rightsize mode")
+	}
+	seen := make(map[string]bool, len(profile.Containers))
+	for _, container := range profile.Containers {
+		if container.Name == "" {
+			return fmt.Errorf("rightsizeProfile container name cannot be empty")
+		}
+		if seen[container.Name] {
+			return fmt.Errorf("rightsizeProfile lists container %q more than once", container.Name)
+		}
+		seen[container.Name] = true
+		if len(container.Requests) == 0 && len(container.Limits) == 0 {
+			return fmt.Errorf("rightsizeProfile container %q sets no requests or limits", container.Name)
+		}
+	}
+	return nil
+}
+
+// applyScaleDown scales the target down according to the scale down mode
+func (r *CronJobScaleDownReconciler) applyScaleDown(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	target := utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}
+	if scaleDownMode(cronJobScaleDown) == cronschedulesv1.ScaleDownModeRightsize {
+		return k8sClient.RightsizeTargetResource(ctx, target, cronJobScaleDown.Spec.RightsizeProfile)
+	}
+	return k8sClient.ScaleDownTargetResource(ctx, target)
+}
+
+// applyScaleUp reverts what applyScaleDown changed on the target
+func (r *CronJobScaleDownReconciler) applyScaleUp(ctx context.Context, k8sClient *utils.K8sClient, cronJobScaleDown *cronschedulesv1.CronJobScaleDown) error {
+	target := utils.TargetObject{TargetRef: *cronJobScaleDown.Spec.TargetRef}
+	if scaleDownMode(cronJobScaleDown) == cronschedulesv1.ScaleDownModeRightsize {
+		return k8sClient.RestoreTargetResources(ctx, target)
+	}
+	return k8sClient.ScaleUpTargetResource(ctx, target)
+}
+
+func scaleDownMode(cronJobScaleDown *cronschedulesv1.CronJobScaleDown) string {
+	if cronJobScaleDown.Spec.ScaleDownMode == "" {
+		return cronschedulesv1.ScaleDownModeReplicas
+	}
+	return cronJobScaleDown.Spec.ScaleDownMode
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// scaleLimiterRetryInterval is how often a deferred or in-flight scale-up is re-checked
+	scaleLimiterRetryInterval = 15 * time.Second
+	// scaleLimiterWaiterTTL drops queued entries whose reconciles stopped asking for a slot
+	scaleLimiterWaiterTTL = 4 * scaleLimiterRetryInterval
+)
+
+// ScaleLimiter limits how many scale-ups run at the same time across all
+// CronJobScaleDown resources handled by the manager, and spreads scale events
+// that share a schedule over a stagger window.
+//
+// A slot is taken when a scale-up starts and held until the target reports ready
+// (or the hold timeout passes). Reconciles that find no free slot are queued by
+// Spec.Priority, then by arrival, and retried.
+//
+// A nil *ScaleLimiter imposes no limits.
+type ScaleLimiter struct {
+	maxConcurrent int
+	staggerWindow time.Duration
+	holdTimeout   time.Duration
+
+	mu      sync.Mutex
+	holders map[string]time.Time
+	waiting map[string]*scaleWaiter
+	now     func() time.Time
+}
+
+type scaleWaiter struct {
+	priority int32
+	since    time.Time
+	lastSeen time.Time
+}
+
+// NewScaleLimiter returns a limiter allowing maxConcurrent simultaneous scale-ups
+// (0 means unlimited) and delaying each scale event by a per-resource offset
+// within staggerWindow (0 disables staggering). Slots are released automatically
+// after holdTimeout.
+func NewScaleLimiter(maxConcurrent int, staggerWindow, holdTimeout time.Duration) *ScaleLimiter {
+	return &ScaleLimiter{
+		maxConcurrent: maxConcurrent,
+		staggerWindow: staggerWindow,
+		holdTimeout:   holdTimeout,
+		holders:       make(map[string]time.Time),
+		waiting:       make(map[string]*scaleWaiter),
+		now:           time.Now,
+	}
+}
+
+// StaggerDelay returns the deterministic offset applied to the scale events of the given resource key
+func (l *ScaleLimiter) StaggerDelay(key string) time.Duration {
+	if l == nil || l.staggerWindow < time.Second {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return time.Duration(uint64(h.Sum32())%uint64(l.staggerWindow/time.Second)) * time.Second
+}
+
+// Acquire tries to take a scale-up slot for key. It returns false when the caller
+// has to wait; the caller is then kept in the queue until it acquires a slot or
+// stops retrying.
+func (l *ScaleLimiter) Acquire(key string, priority int32) bool {
+	if l == nil || l.maxConcurrent <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.expireLocked(now)
+
+	if _, ok := l.holders[key]; ok {
+		return true
+	}
+
+	w, ok := l.waiting[key]
+	if !ok {
+		w = &scaleWaiter{since: now}
+		l.waiting[key] = w
+	}
+	w.priority = priority
+	w.lastSeen = now
+
+	free := l.maxConcurrent - len(l.holders)
+	if free > 0 && l.rankLocked(key) < free {
+		delete(l.waiting, key)
+		l.holders[key] = now
+		l.updateMetricsLocked()
+		return true
+	}
+
+	l.updateMetricsLocked()
+	return false
+}
+
+// Release frees the slot held by key and removes it from the queue
+func (l *ScaleLimiter) Release(key string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.holders, key)
+	delete(l.waiting, key)
+	l.updateMetricsLocked()
+}
+
+// Holds reports whether key currently holds a scale-up slot
+func (l *ScaleLimiter) Holds(key string) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireLocked(l.now())
+	_, ok := l.holders[key]
+	return ok
+}
+
+// Pending reports whether key holds a slot or is queued for one
+func (l *ScaleLimiter) Pending(key string) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireLocked(l.now())
+	_, holding := l.holders[key]
+	_, queued := l.waiting[key]
+	return holding || queued
+}
+
+// QueueDepth returns the number of resources waiting for a scale-up slot
+func (l *ScaleLimiter) QueueDepth() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.waiting)
+}
+
+// rankLocked returns how many queued entries are ahead of key
+func (l *ScaleLimiter) rankLocked(key string) int {
+	keys := make([]string, 0, len(l.waiting))
+	for k := range l.waiting {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := l.waiting[keys[i]], l.waiting[keys[j]]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if !a.since.Equal(b.since) {
+			return a.since.Before(b.since)
+		}
+		return keys[i] < keys[j]
+	})
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return len(keys)
+}
+
+// expireLocked drops slots held longer than the hold timeout and waiters that stopped retrying
+func (l *ScaleLimiter) expireLocked(now time.Time) {
+	for k, since := range l.holders {
+		if l.holdTimeout > 0 && now.Sub(since) > l.holdTimeout {
+			delete(l.holders, k)
+		}
+	}
+	for k, w := range l.waiting {
+		if now.Sub(w.lastSeen) > scaleLimiterWaiterTTL {
+			delete(l.waiting, k)
+		}
+	}
+	l.updateMetricsLocked()
+}
+
+func (l *ScaleLimiter) updateMetricsLocked() {
+	scaleUpQueueDepth.Set(float64(len(l.waiting)))
+	scaleUpsInFlight.Set(float64(len(l.holders)))
+}
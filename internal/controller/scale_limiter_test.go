@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScaleLimiter", func() {
+	var (
+		limiter *ScaleLimiter
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+		limiter = NewScaleLimiter(1, 10*time.Minute, 5*time.Minute)
+		limiter.now = func() time.Time { return now }
+	})
+
+	It("should impose no limits when nil", func() {
+		var nilLimiter *ScaleLimiter
+		Expect(nilLimiter.Acquire("default/a", 0)).To(BeTrue())
+		Expect(nilLimiter.StaggerDelay("default/a")).To(BeZero())
+		Expect(nilLimiter.Pending("default/a")).To(BeFalse())
+	})
+
+	It("should compute a deterministic stagger delay within the window", func() {
+		delay := limiter.StaggerDelay("default/a")
+		Expect(delay).To(Equal(limiter.StaggerDelay("default/a")))
+		Expect(delay).To(BeNumerically(">=", 0))
+		Expect(delay).To(BeNumerically("<", 10*time.Minute))
+	})
+
+	It("should queue waiters by priority once the limit is reached", func() {
+		Expect(limiter.Acquire("default/a", 0)).To(BeTrue())
+		Expect(limiter.Acquire("default/b", 0)).To(BeFalse())
+		now = now.Add(time.Second)
+		Expect(limiter.Acquire("default/c", 10)).To(BeFalse())
+		Expect(limiter.QueueDepth()).To(Equal(2))
+
+		limiter.Release("default/a")
+		Expect(limiter.Acquire("default/b", 0)).To(BeFalse())
+		Expect(limiter.Acquire("default/c", 10)).To(BeTrue())
+		Expect(limiter.Holds("default/c")).To(BeTrue())
+		Expect(limiter.QueueDepth()).To(Equal(1))
+	})
+
+	It("should release slots after the hold timeout", func() {
+		Expect(limiter.Acquire("default/a", 0)).To(BeTrue())
+		Expect(limiter.Acquire("default/b", 0)).To(BeFalse())
+
+		now = now.Add(6 * time.Minute)
+		Expect(limiter.Holds("default/a")).To(BeFalse())
+	})
+
+	It("should drop waiters that stopped retrying", func() {
+		Expect(limiter.Acquire("default/a", 0)).To(BeTrue())
+		Expect(limiter.Acquire("default/b", 100)).To(BeFalse())
+
+		now = now.Add(2 * scaleLimiterWaiterTTL)
+		limiter.Release("default/a")
+		Expect(limiter.Acquire("default/c", 0)).To(BeTrue())
+	})
+})
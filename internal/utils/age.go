@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// ageReference returns the time the age of obj is measured from according to the age source. It is never
+// earlier than the creation time, which is also used when obj has no activity of the selected source.
+func (c *K8sClient) ageReference(ctx context.Context, obj client.Object, ageSource string) time.Time {
+	created := obj.GetCreationTimestamp().Time
+
+	var last time.Time
+	switch ageSource {
+	case cronschedulesv1.AgeSourceLastUpdate:
+		last = lastUpdateTime(obj)
+	case cronschedulesv1.AgeSourceLastRollout:
+		last = lastConditionTime(obj)
+	case cronschedulesv1.AgeSourceLastPodStart:
+		var err error
+		if last, err = c.lastPodStart(ctx, obj); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to find last pod start, using creation time", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		}
+	}
+	if last.After(created) {
+		return last
+	}
+	return created
+}
+
+// lastConditionTime returns the latest update or transition time of the status conditions of obj, such as
+// the Progressing condition of a Deployment updated by every rollout
+func lastConditionTime(obj client.Object) time.Time {
+	content, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return time.Time{}
+		}
+		content = &unstructured.Unstructured{Object: converted}
+	}
+	conditions, _, _ := unstructured.NestedSlice(content.Object, "status", "conditions")
+
+	var last time.Time
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"lastUpdateTime", "lastTransitionTime"} {
+			value, _ := fields[key].(string)
+			if t, err := time.Parse(time.RFC3339, value); err == nil && t.After(last) {
+				last = t
+			}
+		}
+	}
+	return last
+}
+
+// lastPodStart returns the latest start of obj if it is a Pod, or of the pods selected by it if it is a
+// workload or a Service, listed from the API server rather than a cluster-wide pod cache
+func (c *K8sClient) lastPodStart(ctx context.Context, obj client.Object) (time.Time, error) {
+	if pod, ok := obj.(*corev1.Pod); ok {
+		return podStartTime(pod), nil
+	}
+	selector, err := podSelector(obj)
+	if err != nil || selector == nil || selector.Empty() {
+		return time.Time{}, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.uncachedReader().List(ctx, pods, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for i := range pods.Items {
+		if t := podStartTime(&pods.Items[i]); t.After(last) {
+			last = t
+		}
+	}
+	return last, nil
+}
+
+// podSelector returns the selector of the pods of a workload or Service, or nil for other objects
+func podSelector(obj client.Object) (labels.Selector, error) {
+	var selector *metav1.LabelSelector
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		selector = o.Spec.Selector
+	case *appsv1.StatefulSet:
+		selector = o.Spec.Selector
+	case *appsv1.DaemonSet:
+		selector = o.Spec.Selector
+	case *batchv1.Job:
+		selector = o.Spec.Selector
+	case *corev1.Service:
+		if len(o.Spec.Selector) == 0 {
+			return nil, nil
+		}
+		return labels.SelectorFromSet(o.Spec.Selector), nil
+	}
+	if selector == nil {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// podStartTime returns the latest start of the pod or of one of its containers, e.g. after a restart
+func podStartTime(pod *corev1.Pod) time.Time {
+	var last time.Time
+	if pod.Status.StartTime != nil {
+		last = pod.Status.StartTime.Time
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if running := status.State.Running; running != nil && running.StartedAt.After(last) {
+			last = running.StartedAt.Time
+		}
+	}
+	return last
+}
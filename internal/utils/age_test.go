@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestAgeSource(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	created := metav1.NewTime(time.Now().Add(-365 * Day))
+	yesterday := metav1.NewTime(time.Now().Add(-Day))
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "settings", Namespace: "default", CreationTimestamp: created,
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, Time: &yesterday}},
+	}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", CreationTimestamp: created},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, LastUpdateTime: yesterday, LastTransitionTime: created},
+		}},
+	}
+	idle := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "idle", Namespace: "default", CreationTimestamp: created},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "idle"}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "default", Labels: map[string]string{"app": "api"}, CreationTimestamp: created},
+		Status: corev1.PodStatus{
+			StartTime: &created,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "api",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: yesterday}},
+			}},
+		},
+	}
+	k8sClient := newOrphanTestClient(pod)
+
+	tests := []struct {
+		name      string
+		obj       client.Object
+		ageSource string
+		want      bool
+	}{
+		{name: "creation", obj: configMap, ageSource: cronschedulesv1.AgeSourceCreation, want: true},
+		{name: "default is creation", obj: configMap, ageSource: "", want: true},
+		{name: "recently updated", obj: configMap, ageSource: cronschedulesv1.AgeSourceLastUpdate, want: false},
+		{name: "recent rollout", obj: deployment, ageSource: cronschedulesv1.AgeSourceLastRollout, want: false},
+		{name: "no rollout falls back to creation", obj: idle, ageSource: cronschedulesv1.AgeSourceLastRollout, want: true},
+		{name: "recently started pod", obj: deployment, ageSource: cronschedulesv1.AgeSourceLastPodStart, want: false},
+		{name: "no pods falls back to creation", obj: idle, ageSource: cronschedulesv1.AgeSourceLastPodStart, want: true},
+		{name: "pod restarted recently", obj: pod, ageSource: cronschedulesv1.AgeSourceLastPodStart, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orphanConfig := &cronschedulesv1.CleanupConfig{
+				CleanupOrphanResources: true,
+				OrphanResourceMaxAge:   "7d",
+				AgeSource:              tt.ageSource,
+			}
+			if got := k8sClient.isOrphanResourceForCleanup(ctx, tt.obj, orphanConfig); got != tt.want {
+				t.Errorf("isOrphanResourceForCleanup() = %v, want %v", got, tt.want)
+			}
+
+			annotated := tt.obj.DeepCopyObject().(client.Object)
+			annotated.SetAnnotations(map[string]string{"cleanup-after": "7d"})
+			annotationConfig := &cronschedulesv1.CleanupConfig{AnnotationKey: "cleanup-after", AgeSource: tt.ageSource}
+			if got := k8sClient.shouldCleanupResource(ctx, annotated, annotationConfig); got != tt.want {
+				t.Errorf("shouldCleanupResource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLastUpdateIgnoresOwnChanges checks that the operator's own patches don't reset the age of the resources
+// it notifies about or quarantines
+func TestLastUpdateIgnoresOwnChanges(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	now := time.Now()
+	userUpdate := metav1.NewTime(now.Add(-10 * Day))
+
+	resource := func(obj client.Object, name string, annotations map[string]string) client.Object {
+		obj.SetName(name)
+		obj.SetNamespace("default")
+		obj.SetCreationTimestamp(metav1.NewTime(now.Add(-20 * Day)))
+		obj.SetAnnotations(annotations)
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, Time: &userUpdate}})
+		return obj
+	}
+	k8sClient := newOrphanTestClient(
+		resource(&corev1.ConfigMap{}, "notified", map[string]string{"cleanup-after": "10d12h"}),
+		resource(&corev1.Service{}, "quarantined", nil),
+	)
+	// Record the operator's patches and a status update in the managed fields, as the API server does
+	k8sClient.Client = interceptor.NewClient(k8sClient.Client.(client.WithWatch), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if err := c.Patch(ctx, obj, patch, opts...); err != nil {
+				return err
+			}
+			changed := metav1.NewTime(time.Now())
+			obj.SetManagedFields(append(obj.GetManagedFields(),
+				metav1.ManagedFieldsEntry{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, Time: &changed},
+				metav1.ManagedFieldsEntry{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", Time: &changed},
+			))
+			return c.Update(ctx, obj)
+		},
+	})
+	get := func(obj client.Object, name string) {
+		t.Helper()
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("pending deletion notice", func(t *testing.T) {
+		cleanupConfig := &cronschedulesv1.CleanupConfig{
+			AnnotationKey: "cleanup-after",
+			ResourceTypes: []string{"ConfigMap"},
+			AgeSource:     cronschedulesv1.AgeSourceLastUpdate,
+			WarnBefore:    "1d",
+		}
+		for run := 1; run <= 2; run++ {
+			plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+			if result := k8sClient.ExecuteCleanupPlan(ctx, plan, cleanupConfig); len(result.UpcomingDeletions) != 1 {
+				t.Errorf("run %d: upcoming deletions = %v, want the notified ConfigMap", run, result.UpcomingDeletions)
+			}
+			configMap := &corev1.ConfigMap{}
+			get(configMap, "notified")
+			if _, ok := configMap.Annotations[PendingDeletionAnnotation]; !ok {
+				t.Errorf("run %d: the notice should be kept, annotations %v", run, configMap.Annotations)
+			}
+		}
+	})
+
+	t.Run("quarantine", func(t *testing.T) {
+		cleanupConfig := &cronschedulesv1.CleanupConfig{
+			ResourceTypes:          []string{"Service"},
+			CleanupOrphanResources: true,
+			OrphanResourceMaxAge:   "7d",
+			AgeSource:              cronschedulesv1.AgeSourceLastUpdate,
+			QuarantinePeriod:       "1d",
+		}
+		run := func() CleanupResult {
+			t.Helper()
+			plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+			return k8sClient.ExecuteCleanupPlan(ctx, plan, cleanupConfig)
+		}
+		if result := run(); result.Quarantined != 1 {
+			t.Fatalf("first run = %+v, want the Service quarantined", result)
+		}
+
+		service := &corev1.Service{}
+		get(service, "quarantined")
+		service.Annotations[quarantineUntilAnnotation] = now.Add(-time.Minute).UTC().Format(time.RFC3339)
+		if err := k8sClient.Update(ctx, service); err != nil {
+			t.Fatal(err)
+		}
+		if result := run(); result.Deleted != 1 {
+			t.Errorf("second run = %+v, want the Service deleted at the end of its quarantine", result)
+		}
+	})
+}
@@ -0,0 +1,634 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// Archives are written as Secrets or ConfigMaps
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=create
+
+const (
+	// ArchiveLabel marks the Secrets and ConfigMaps holding cleanup archives
+	ArchiveLabel = "cronjob-scale-down-operator/archive"
+	// RestoredFromAnnotation is set on restored objects to the archive they were restored from
+	RestoredFromAnnotation = "cronjob-scale-down-operator/restored-from"
+
+	// archiveOwnerAnnotation holds the namespace/name of the CronJobScaleDown that wrote an archive
+	archiveOwnerAnnotation = "cronjob-scale-down-operator/archive-owner"
+	// archivePartsAnnotation holds the number of parts of an archive split across several objects
+	archivePartsAnnotation = "cronjob-scale-down-operator/archive-parts"
+	// archiveSignatureAnnotation holds the HMAC of an archive part, see signArchive
+	archiveSignatureAnnotation = "cronjob-scale-down-operator/archive-signature"
+	// archiveSignatureSuffix is appended to the name of an archive file for the file holding its HMAC
+	archiveSignatureSuffix = ".sig"
+	// archiveDataKey is the key of the compressed YAML in archive Secrets and ConfigMaps
+	archiveDataKey = "objects.yaml.gz"
+	// archiveFileSuffix is the extension of archive files
+	archiveFileSuffix = ".yaml.gz"
+	// defaultArchiveRetention is how long archives are kept when no retention is set
+	defaultArchiveRetention = 30 * Day
+
+	// DefaultArchiveRoot is the directory Directory sink archives are written under when none is configured
+	DefaultArchiveRoot = "/archives"
+)
+
+// ArchiveSettings are the operator-wide archive settings, set by the administrator rather than by
+// CronJobScaleDown authors
+type ArchiveSettings struct {
+	// SigningKey authenticates archives with an HMAC. Archives are written unsigned without it, and
+	// restores are refused, since anyone able to create a Secret could otherwise forge an archive.
+	SigningKey []byte
+
+	// Namespace is where CronJobScaleDowns may keep their archives besides their own namespace. Optional.
+	Namespace string
+
+	// Root is the directory paths of the Directory sink are relative to, DefaultArchiveRoot when empty
+	Root string
+}
+
+// ValidateArchivePath checks that a Directory sink path stays under the archive root: it must be
+// relative and must not contain ".." elements
+func ValidateArchivePath(path string) error {
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("archive path %q must be relative to the archive root", path)
+	}
+	if slices.Contains(strings.Split(filepath.ToSlash(path), "/"), "..") {
+		return fmt.Errorf("archive path %q must not contain ..", path)
+	}
+	return nil
+}
+
+// maxArchivePartBytes bounds the uncompressed YAML stored in one archive Secret or ConfigMap,
+// well below the 1MiB object size limit
+var maxArchivePartBytes = 900 * 1024
+
+// ArchiveName returns the name of the archive a cleanup run of the CronJobScaleDown writes at now
+func ArchiveName(owner string, now time.Time) string {
+	// Leave room for the suffixes within the 253 characters of an object name
+	if len(owner) > 200 {
+		owner = strings.TrimRight(owner[:200], "-.")
+	}
+	return fmt.Sprintf("%s-archive-%s", owner, now.UTC().Format("20060102-150405"))
+}
+
+// ArchivePlan writes the objects planned for deletion to the archive sink and returns the archive name.
+// Objects too large for a Secret or ConfigMap sink are withdrawn from the plan, with their group, and
+// reported as skipped, since nothing is deleted without being archived. Nothing is written when there
+// are no planned deletions.
+func (c *K8sClient) ArchivePlan(ctx context.Context, plan *CleanupPlan, archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName, now time.Time) (string, error) {
+	logger := log.FromContext(ctx)
+
+	archived := make(map[client.Object][]byte, len(plan.Deletions))
+	for _, obj := range slices.Clone(plan.Deletions) {
+		document, err := c.archiveDocument(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to archive %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+		if archive.Sink != cronschedulesv1.ArchiveSinkDirectory && len(document) > maxArchivePartBytes {
+			logger.Info("Object too large to archive, keeping it", "type", objectKind(obj), "name", obj.GetName(),
+				"namespace", obj.GetNamespace(), "bytes", len(document))
+			plan.withdraw(obj, fmt.Sprintf("too large to archive: %d bytes", len(document)))
+			continue
+		}
+		archived[obj] = document
+	}
+	if len(plan.Deletions) == 0 {
+		return "", nil
+	}
+
+	documents := make([][]byte, 0, len(plan.Deletions))
+	for _, obj := range plan.Deletions {
+		documents = append(documents, archived[obj])
+	}
+
+	name := ArchiveName(owner.Name, now)
+	if archive.Sink == cronschedulesv1.ArchiveSinkDirectory {
+		path, err := c.archiveFilePath(archive, owner, name)
+		if err != nil {
+			return "", err
+		}
+		if err := c.writeArchiveFile(path, owner, name, documents); err != nil {
+			return "", err
+		}
+	} else if err := c.writeArchiveObjects(ctx, archive, owner, name, documents); err != nil {
+		return "", err
+	}
+
+	logger.Info("Archived resources before cleanup", "archive", name, "sink", archiveSink(archive), "resources", len(documents))
+	return name, nil
+}
+
+// archiveDocument serializes obj to YAML without its status and server-set metadata
+func (c *K8sClient) archiveDocument(obj client.Object) ([]byte, error) {
+	u, err := c.toUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation",
+		"selfLink", "deletionTimestamp", "deletionGracePeriodSeconds"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	return yaml.Marshal(u.Object)
+}
+
+// toUnstructured returns a copy of obj as an unstructured object with its kind set
+func (c *K8sClient) toUnstructured(obj client.Object) (*unstructured.Unstructured, error) {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.DeepCopy().Object
+	} else if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+// writeArchiveObjects stores the documents in compressed Secrets or ConfigMaps, split into parts
+// that fit the object size limit. The first part is named after the archive. No document is larger
+// than maxArchivePartBytes, see ArchivePlan.
+func (c *K8sClient) writeArchiveObjects(ctx context.Context, archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName, name string, documents [][]byte) error {
+	var parts [][]byte
+	var current []byte
+	for _, document := range documents {
+		if len(current) > 0 && len(current)+len(document)+4 > maxArchivePartBytes {
+			parts = append(parts, current)
+			current = nil
+		}
+		current = append(current, "---\n"...)
+		current = append(current, document...)
+	}
+	parts = append(parts, current)
+
+	for i, part := range parts {
+		data, err := gzipBytes(part)
+		if err != nil {
+			return err
+		}
+		meta := metav1.ObjectMeta{
+			Name:      archivePartName(name, i),
+			Namespace: archiveNamespace(archive, owner),
+			Labels:    map[string]string{ArchiveLabel: "true"},
+			Annotations: map[string]string{
+				archiveOwnerAnnotation: owner.String(),
+				archivePartsAnnotation: strconv.Itoa(len(parts)),
+			},
+		}
+		if signature := c.signArchive(owner, meta.Name, len(parts), data); signature != "" {
+			meta.Annotations[archiveSignatureAnnotation] = signature
+		}
+
+		var obj client.Object
+		if archive.Sink == cronschedulesv1.ArchiveSinkConfigMap {
+			obj = &corev1.ConfigMap{ObjectMeta: meta, BinaryData: map[string][]byte{archiveDataKey: data}}
+		} else {
+			obj = &corev1.Secret{ObjectMeta: meta, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{archiveDataKey: data}}
+		}
+		if err := c.Create(ctx, obj); err != nil {
+			return fmt.Errorf("failed to write archive %s/%s: %w", meta.Namespace, meta.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeArchiveFile stores the documents in a compressed file, creating its directory if needed,
+// and its signature next to it
+func (c *K8sClient) writeArchiveFile(path string, owner types.NamespacedName, name string, documents [][]byte) error {
+	data, err := gzipBytes(bytes.Join(append([][]byte{nil}, documents...), []byte("---\n")))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if signature := c.signArchive(owner, name, 1, data); signature != "" {
+		if err := os.WriteFile(path+archiveSignatureSuffix, []byte(signature), 0o640); err != nil {
+			return fmt.Errorf("failed to write archive signature %s: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", path, err)
+	}
+	return nil
+}
+
+// signArchive returns the HMAC of an archive part, binding its data to the CronJobScaleDown that wrote it,
+// its name and the number of parts, or an empty string when no signing key is configured
+func (c *K8sClient) signArchive(owner types.NamespacedName, name string, parts int, data []byte) string {
+	if len(c.ArchiveSettings.SigningKey) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.ArchiveSettings.SigningKey)
+	fmt.Fprintf(mac, "%s\x00%s\x00%d\x00", owner, name, parts)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyArchive checks the signature of an archive part
+func (c *K8sClient) verifyArchive(owner types.NamespacedName, name string, parts int, data []byte, signature string) error {
+	if len(c.ArchiveSettings.SigningKey) == 0 {
+		return fmt.Errorf("restoring archives requires the operator's archive signing key")
+	}
+	expected := c.signArchive(owner, name, parts, data)
+	if signature == "" || !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return fmt.Errorf("archive %s has no valid signature", name)
+	}
+	return nil
+}
+
+// PruneArchives deletes the CronJobScaleDown's archives older than the retention and returns how many were deleted
+func (c *K8sClient) PruneArchives(ctx context.Context, archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName, now time.Time) (int, error) {
+	logger := log.FromContext(ctx)
+
+	retention := defaultArchiveRetention
+	if archive.Retention != "" {
+		var err error
+		if retention, err = ParseDuration(archive.Retention); err != nil {
+			return 0, fmt.Errorf("invalid archive retention: %w", err)
+		}
+	}
+	cutoff := now.Add(-retention)
+
+	pruned := 0
+	if archive.Sink == cronschedulesv1.ArchiveSinkDirectory {
+		path, err := c.archiveFilePath(archive, owner, "")
+		if err != nil {
+			return 0, err
+		}
+		dir := filepath.Dir(path)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("failed to read archive directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isArchiveFileOf(entry.Name(), owner.Name) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return pruned, fmt.Errorf("failed to remove archive %s: %w", entry.Name(), err)
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name()+archiveSignatureSuffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return pruned, fmt.Errorf("failed to remove archive signature %s: %w", entry.Name(), err)
+			}
+			logger.Info("Pruned cleanup archive", "archive", entry.Name())
+			pruned++
+		}
+		return pruned, nil
+	}
+
+	var list client.ObjectList = &corev1.SecretList{}
+	if archive.Sink == cronschedulesv1.ArchiveSinkConfigMap {
+		list = &corev1.ConfigMapList{}
+	}
+	if err := c.List(ctx, list, client.InNamespace(archiveNamespace(archive, owner)), client.MatchingLabels{ArchiveLabel: "true"}); err != nil {
+		return 0, fmt.Errorf("failed to list archives: %w", err)
+	}
+	for _, item := range c.extractItemsFromList(list) {
+		if item.GetAnnotations()[archiveOwnerAnnotation] != owner.String() || !item.GetCreationTimestamp().Time.Before(cutoff) {
+			continue
+		}
+		if err := c.Delete(ctx, item); client.IgnoreNotFound(err) != nil {
+			return pruned, fmt.Errorf("failed to delete archive %s: %w", item.GetName(), err)
+		}
+		logger.Info("Pruned cleanup archive", "archive", item.GetName())
+		pruned++
+	}
+	return pruned, nil
+}
+
+// RestoreResult reports the outcome of an archive restore
+type RestoreResult struct {
+	// Restored is the number of objects recreated
+	Restored int
+	// Existing is the number of objects left alone because they already exist
+	Existing int
+}
+
+// RestoreArchive recreates the objects of one of the CronJobScaleDown's archives. Objects that already
+// exist are left alone. The cleanup annotation and quarantine state are removed from restored objects so
+// the next run doesn't delete them again, and they are annotated with the archive they came from. Their
+// references to owners that no longer exist are removed too, since the garbage collector would delete
+// them right away: an owner restored from the same archive comes back with a new UID.
+func (c *K8sClient) RestoreArchive(ctx context.Context, cleanupConfig *cronschedulesv1.CleanupConfig, owner types.NamespacedName, name string) (RestoreResult, error) {
+	logger := log.FromContext(ctx)
+	result := RestoreResult{}
+
+	archive := cleanupConfig.Archive
+	if archive == nil {
+		return result, fmt.Errorf("cleanupConfig.archive is not configured")
+	}
+
+	data, err := c.readArchive(ctx, archive, owner, name)
+	if err != nil {
+		return result, err
+	}
+	scope, err := c.restoreScope(ctx, cleanupConfig, owner.Namespace)
+	if err != nil {
+		return result, err
+	}
+
+	var errs []error
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return result, fmt.Errorf("failed to decode archive %s: %w", name, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if reason := scope.refuse(obj); reason != "" {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), reason))
+			continue
+		}
+
+		// Objects deleted at the end of their quarantine come back as they were before it
+		liftQuarantine(obj)
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		delete(annotations, cleanupConfig.AnnotationKey)
+		annotations[RestoredFromAnnotation] = name
+		obj.SetAnnotations(annotations)
+		if err := c.dropStaleOwnerReferences(ctx, obj); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err))
+			continue
+		}
+
+		if err := c.Create(ctx, obj); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				result.Existing++
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err))
+			continue
+		}
+		logger.Info("Restored resource from archive", "archive", name, "type", obj.GetKind(), "name", obj.GetName(), "namespace", obj.GetNamespace())
+		result.Restored++
+	}
+	return result, errors.Join(errs...)
+}
+
+// dropStaleOwnerReferences removes the owner references of obj whose owner doesn't exist with the referenced
+// UID. The owners are read from the API server, so that an owner deleted moments ago is not found in the cache.
+func (c *K8sClient) dropStaleOwnerReferences(ctx context.Context, obj *unstructured.Unstructured) error {
+	references := obj.GetOwnerReferences()
+	if len(references) == 0 {
+		return nil
+	}
+
+	kept := make([]metav1.OwnerReference, 0, len(references))
+	for _, reference := range references {
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(schema.FromAPIVersionAndKind(reference.APIVersion, reference.Kind))
+		err := c.uncachedReader().Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: reference.Name}, owner)
+		switch {
+		case err == nil:
+			if owner.UID == reference.UID {
+				kept = append(kept, reference)
+			}
+		case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		default:
+			return fmt.Errorf("failed to check owner %s %s: %w", reference.Kind, reference.Name, err)
+		}
+	}
+	obj.SetOwnerReferences(kept)
+	return nil
+}
+
+// restoreScope holds the kinds and namespaces a CronJobScaleDown cleans up, the only ones it may restore
+type restoreScope struct {
+	kinds      []schema.GroupKind
+	namespaces []string
+}
+
+// restoreScope returns the kinds and namespaces the cleanup config cleans up. Helm releases only contribute
+// their release Secrets, since the kinds of their manifests aren't known in advance.
+func (c *K8sClient) restoreScope(ctx context.Context, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string) (*restoreScope, error) {
+	resourceTypes := slices.Clone(cleanupConfig.ResourceTypes)
+	if unreferenced := cleanupConfig.UnreferencedConfigs; unreferenced != nil {
+		resourceTypes = append(resourceTypes, unreferenced.Kinds...)
+	}
+	if cascade := cleanupConfig.Cascade; cascade != nil {
+		if len(cascade.Kinds) > 0 {
+			resourceTypes = append(resourceTypes, cascade.Kinds...)
+		} else {
+			resourceTypes = append(resourceTypes, cascadeKinds...)
+		}
+	}
+
+	scope := &restoreScope{}
+	for _, resourceType := range resourceTypes {
+		if resourceType == HelmReleaseResourceType {
+			resourceType = "Secret"
+		}
+		groupKind, err := resourceTypeGroupKind(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		scope.kinds = append(scope.kinds, groupKind)
+	}
+
+	namespaces, err := c.ResolveNamespaces(ctx, cleanupConfig, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	scope.namespaces = namespaces
+	return scope, nil
+}
+
+// refuse returns why an archived object can't be restored, or an empty string if it can
+func (s *restoreScope) refuse(obj *unstructured.Unstructured) string {
+	if obj.GroupVersionKind().Group == rbacv1.GroupName {
+		return "RBAC objects are not restored"
+	}
+	if !slices.Contains(s.kinds, obj.GroupVersionKind().GroupKind()) {
+		return "not a kind this CronJobScaleDown cleans up"
+	}
+	if namespace := obj.GetNamespace(); namespace != "" && !slices.Contains(s.namespaces, namespace) {
+		return "not in a namespace this CronJobScaleDown cleans up"
+	}
+	return ""
+}
+
+// readArchive returns the uncompressed YAML of an archive written for the CronJobScaleDown
+func (c *K8sClient) readArchive(ctx context.Context, archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName, name string) ([]byte, error) {
+	if archive.Sink == cronschedulesv1.ArchiveSinkDirectory {
+		if !isArchiveFileOf(name+archiveFileSuffix, owner.Name) || filepath.Base(name) != name {
+			return nil, fmt.Errorf("%s is not an archive of %s", name, owner)
+		}
+		path, err := c.archiveFilePath(archive, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+		}
+		signature, err := os.ReadFile(path + archiveSignatureSuffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read archive signature %s: %w", name, err)
+		}
+		if err := c.verifyArchive(owner, name, 1, data, string(signature)); err != nil {
+			return nil, err
+		}
+		return gunzipBytes(data)
+	}
+
+	var data []byte
+	for i, parts := 0, 1; i < parts; i++ {
+		var obj client.Object = &corev1.Secret{}
+		if archive.Sink == cronschedulesv1.ArchiveSinkConfigMap {
+			obj = &corev1.ConfigMap{}
+		}
+		key := client.ObjectKey{Namespace: archiveNamespace(archive, owner), Name: archivePartName(name, i)}
+		if err := c.Get(ctx, key, obj); err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+		}
+		// Only archives of this CronJobScaleDown can be restored through it
+		if obj.GetLabels()[ArchiveLabel] != "true" || obj.GetAnnotations()[archiveOwnerAnnotation] != owner.String() {
+			return nil, fmt.Errorf("%s is not an archive of %s", key, owner)
+		}
+		if i == 0 {
+			var err error
+			if parts, err = strconv.Atoi(obj.GetAnnotations()[archivePartsAnnotation]); err != nil || parts < 1 {
+				return nil, fmt.Errorf("archive %s has an invalid part count", key)
+			}
+		}
+
+		var compressed []byte
+		switch o := obj.(type) {
+		case *corev1.Secret:
+			compressed = o.Data[archiveDataKey]
+		case *corev1.ConfigMap:
+			compressed = o.BinaryData[archiveDataKey]
+		}
+		if err := c.verifyArchive(owner, key.Name, parts, compressed, obj.GetAnnotations()[archiveSignatureAnnotation]); err != nil {
+			return nil, err
+		}
+		part, err := gunzipBytes(compressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+		}
+		data = append(data, part...)
+	}
+	return data, nil
+}
+
+// archiveSink returns the configured sink, Secret by default
+func archiveSink(archive *cronschedulesv1.CleanupArchive) string {
+	if archive.Sink == "" {
+		return cronschedulesv1.ArchiveSinkSecret
+	}
+	return archive.Sink
+}
+
+// archiveNamespace returns the namespace of the archive Secrets or ConfigMaps
+func archiveNamespace(archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName) string {
+	if archive.Namespace != "" {
+		return archive.Namespace
+	}
+	return owner.Namespace
+}
+
+// archivePartName returns the name of the i-th part of an archive
+func archivePartName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-part-%d", name, i+1)
+}
+
+// archiveFilePath returns the file of an archive with the Directory sink, in a directory per namespace
+// under the archive root
+func (c *K8sClient) archiveFilePath(archive *cronschedulesv1.CleanupArchive, owner types.NamespacedName, name string) (string, error) {
+	if err := ValidateArchivePath(archive.Path); err != nil {
+		return "", err
+	}
+	root := c.ArchiveSettings.Root
+	if root == "" {
+		root = DefaultArchiveRoot
+	}
+	return filepath.Join(root, archive.Path, owner.Namespace, name+archiveFileSuffix), nil
+}
+
+// isArchiveFileOf reports whether the file name is an archive written by the named CronJobScaleDown
+func isArchiveFileOf(fileName, owner string) bool {
+	name, ok := strings.CutSuffix(fileName, archiveFileSuffix)
+	if !ok {
+		return false
+	}
+	prefix := ArchiveName(owner, time.Time{})
+	prefix = prefix[:len(prefix)-len("00010101-000000")]
+	return strings.HasPrefix(name, prefix)
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,526 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestArchiveAndRestore(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		archive   cronschedulesv1.CleanupArchive
+		partBytes int
+	}{
+		{name: "secret sink", archive: cronschedulesv1.CleanupArchive{}},
+		{name: "configmap sink in archive namespace", archive: cronschedulesv1.CleanupArchive{Sink: cronschedulesv1.ArchiveSinkConfigMap, Namespace: "archives"}},
+		{name: "secret sink split into parts", archive: cronschedulesv1.CleanupArchive{}, partBytes: 600},
+		{name: "directory sink", archive: cronschedulesv1.CleanupArchive{Sink: cronschedulesv1.ArchiveSinkDirectory}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.partBytes > 0 {
+				previous := maxArchivePartBytes
+				maxArchivePartBytes = tt.partBytes
+				defer func() { maxArchivePartBytes = previous }()
+			}
+			if tt.archive.Sink == cronschedulesv1.ArchiveSinkDirectory {
+				tt.archive.Path = "cleanup"
+			}
+
+			var objs []client.Object
+			for i := range 3 {
+				objs = append(objs, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        fmt.Sprintf("cm-%d", i),
+						Namespace:   "default",
+						Annotations: map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"},
+					},
+					Data: map[string]string{"key": strings.Repeat("v", 200)},
+				})
+			}
+			k8sClient := newOrphanTestClient(objs...)
+			k8sClient.ArchiveSettings = ArchiveSettings{Root: t.TempDir(), SigningKey: []byte("test-key")}
+
+			plan := &CleanupPlan{}
+			for _, obj := range objs {
+				current := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+					t.Fatal(err)
+				}
+				plan.add(current, cronschedulesv1.CleanupReasonAnnotation)
+			}
+
+			name, err := k8sClient.ArchivePlan(ctx, plan, &tt.archive, owner, now)
+			if err != nil {
+				t.Fatalf("ArchivePlan() error = %v", err)
+			}
+			if name != "nightly-archive-20250701-120000" {
+				t.Errorf("archive name = %s", name)
+			}
+			if tt.partBytes > 0 {
+				second := &corev1.Secret{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "ops", Name: name + "-part-2"}, second); err != nil {
+					t.Errorf("expected the archive to be split: %v", err)
+				}
+			}
+
+			result := k8sClient.ExecuteCleanupPlan(ctx, plan, &cronschedulesv1.CleanupConfig{})
+			if result.Deleted != 3 {
+				t.Fatalf("deleted %d, want 3", result.Deleted)
+			}
+
+			cleanupConfig := &cronschedulesv1.CleanupConfig{
+				AnnotationKey: "cleanup-after",
+				ResourceTypes: []string{"ConfigMap"},
+				Namespaces:    []string{"default"},
+				Archive:       &tt.archive,
+			}
+			restored, err := k8sClient.RestoreArchive(ctx, cleanupConfig, owner, name)
+			if err != nil || restored.Restored != 3 {
+				t.Fatalf("RestoreArchive() = %+v, %v", restored, err)
+			}
+
+			cm := &corev1.ConfigMap{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cm-1"}, cm); err != nil {
+				t.Fatalf("restored configmap not found: %v", err)
+			}
+			if cm.Data["key"] != strings.Repeat("v", 200) {
+				t.Errorf("restored data = %q", cm.Data["key"])
+			}
+			if _, ok := cm.Annotations["cleanup-after"]; ok {
+				t.Errorf("cleanup annotation kept on restored object")
+			}
+			if cm.Annotations[RestoredFromAnnotation] != name {
+				t.Errorf("restored-from annotation = %q", cm.Annotations[RestoredFromAnnotation])
+			}
+
+			// Restoring again leaves existing objects alone
+			restored, err = k8sClient.RestoreArchive(ctx, cleanupConfig, owner, name)
+			if err != nil || restored.Existing != 3 || restored.Restored != 0 {
+				t.Errorf("second RestoreArchive() = %+v, %v", restored, err)
+			}
+		})
+	}
+}
+
+func TestArchiveAndRestoreCascadeGroup(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	preview := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "preview",
+		Namespace:   "default",
+		UID:         "preview-uid",
+		Labels:      map[string]string{instanceLabel: "preview"},
+		Annotations: map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"},
+	}}
+	preview.Spec.Template.Labels = map[string]string{"app": "preview"}
+	live := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "main", Namespace: "default", UID: "main-uid"}}
+	live.Spec.Template.Labels = map[string]string{"app": "main"}
+	values := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "preview-values",
+		Namespace: "default",
+		Labels:    map[string]string{instanceLabel: "preview"},
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "preview", UID: "preview-uid"},
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "main", UID: "main-uid"},
+		},
+	}}
+	k8sClient := newOrphanTestClient(preview, live, values)
+	k8sClient.ArchiveSettings = ArchiveSettings{SigningKey: []byte("test-key")}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{"Deployment"},
+		Namespaces:    []string{"default"},
+		Cascade:       &cronschedulesv1.CascadeCleanup{},
+		Archive:       &cronschedulesv1.CleanupArchive{},
+	}
+
+	plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+	name, err := k8sClient.ArchivePlan(ctx, plan, cleanupConfig.Archive, owner, now)
+	if err != nil {
+		t.Fatalf("ArchivePlan() error = %v", err)
+	}
+	if result := k8sClient.ExecuteCleanupPlan(ctx, plan, cleanupConfig); result.Deleted != 2 {
+		t.Fatalf("deleted %d, want the Deployment and its ConfigMap", result.Deleted)
+	}
+
+	restored, err := k8sClient.RestoreArchive(ctx, cleanupConfig, owner, name)
+	if err != nil || restored.Restored != 2 {
+		t.Fatalf("RestoreArchive() = %+v, %v", restored, err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(values), cm); err != nil {
+		t.Fatalf("restored configmap not found: %v", err)
+	}
+	// The restored Deployment has a new UID, so only the reference to the live owner is kept
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != "main" {
+		t.Errorf("restored owner references = %+v, want only the live main Deployment", cm.OwnerReferences)
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(preview), &appsv1.Deployment{}); err != nil {
+		t.Errorf("restored deployment not found: %v", err)
+	}
+}
+
+func TestArchivePlanSkipsObjectsTooLarge(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	previous := maxArchivePartBytes
+	maxArchivePartBytes = 600
+	defer func() { maxArchivePartBytes = previous }()
+
+	configMap := func(name string, size int) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"key": strings.Repeat("v", size)},
+		}
+	}
+	small, large := configMap("small", 10), configMap("large", 1000)
+	workload := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}
+	groupLarge := configMap("api-config", 1000)
+	k8sClient := newOrphanTestClient(small, large, workload, groupLarge)
+
+	plan := &CleanupPlan{}
+	plan.add(small, cronschedulesv1.CleanupReasonAnnotation)
+	plan.add(large, cronschedulesv1.CleanupReasonAnnotation)
+	plan.addGroup(workload, cronschedulesv1.CleanupReasonAnnotation, &cleanupGroup{name: "Deployment/api", related: []client.Object{groupLarge}})
+
+	name, err := k8sClient.ArchivePlan(ctx, plan, &cronschedulesv1.CleanupArchive{}, owner, now)
+	if err != nil {
+		t.Fatalf("ArchivePlan() error = %v", err)
+	}
+	if name == "" || len(plan.Deletions) != 1 || plan.Deletions[0] != small {
+		t.Fatalf("ArchivePlan() = %q with deletions %v, want only small archived", name, plan.Deletions)
+	}
+	skipped := map[string]string{}
+	for _, resource := range plan.Skipped {
+		skipped[resource.Name] = resource.Reason
+	}
+	if !strings.HasPrefix(skipped["large"], "too large to archive") ||
+		!strings.HasPrefix(skipped["api-config"], "too large to archive") ||
+		!strings.HasPrefix(skipped["api"], "ConfigMap api-config is too large to archive") {
+		t.Errorf("skipped = %v, want large and the api group kept as too large to archive", skipped)
+	}
+	if len(plan.groups) != 0 {
+		t.Errorf("expected the api group to be withdrawn, got %v", plan.groups)
+	}
+}
+
+func TestArchiveDocumentStripsServerFields(t *testing.T) {
+	k8sClient := newOrphanTestClient()
+	now := metav1.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "job-pod",
+			Namespace:         "default",
+			UID:               "uid-1",
+			ResourceVersion:   "42",
+			CreationTimestamp: now,
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+
+	document, err := k8sClient.archiveDocument(pod)
+	if err != nil {
+		t.Fatalf("archiveDocument() error = %v", err)
+	}
+	text := string(document)
+	for _, field := range []string{"status:", "resourceVersion", "uid:", "creationTimestamp", "managedFields"} {
+		if strings.Contains(text, field) {
+			t.Errorf("archived document contains %s:\n%s", field, text)
+		}
+	}
+	for _, field := range []string{"apiVersion: v1", "kind: Pod", "name: job-pod"} {
+		if !strings.Contains(text, field) {
+			t.Errorf("archived document is missing %s:\n%s", field, text)
+		}
+	}
+}
+
+func TestRestoreArchiveRejectsOtherOwners(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	foreign := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:        "db-credentials",
+		Namespace:   "ops",
+		Labels:      map[string]string{ArchiveLabel: "true"},
+		Annotations: map[string]string{archiveOwnerAnnotation: "ops/other", archivePartsAnnotation: "1"},
+	}}
+	k8sClient := newOrphanTestClient(foreign)
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+
+	cleanupConfig := &cronschedulesv1.CleanupConfig{Archive: &cronschedulesv1.CleanupArchive{}}
+	if _, err := k8sClient.RestoreArchive(ctx, cleanupConfig, owner, "db-credentials"); err == nil {
+		t.Error("expected archives of other CronJobScaleDowns to be rejected")
+	}
+
+	k8sClient.ArchiveSettings.Root = t.TempDir()
+	cleanupConfig.Archive = &cronschedulesv1.CleanupArchive{Sink: cronschedulesv1.ArchiveSinkDirectory}
+	if _, err := k8sClient.RestoreArchive(ctx, cleanupConfig, owner, "../other/other-archive-20250701-120000"); err == nil {
+		t.Error("expected paths outside the archive directory to be rejected")
+	}
+}
+
+func TestRestoreArchiveAuthenticationAndScope(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	key := []byte("test-key")
+
+	// archive writes an archive of a deleted ConfigMap with the signing key and returns the archive Secret
+	archive := func(k8sClient *K8sClient, signingKey []byte) *corev1.Secret {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+		if err := k8sClient.Create(ctx, cm); err != nil {
+			t.Fatal(err)
+		}
+		plan := &CleanupPlan{}
+		plan.add(cm, cronschedulesv1.CleanupReasonAnnotation)
+		k8sClient.ArchiveSettings.SigningKey = signingKey
+		name, err := k8sClient.ArchivePlan(ctx, plan, &cronschedulesv1.CleanupArchive{}, owner, now)
+		if err != nil {
+			t.Fatalf("ArchivePlan() error = %v", err)
+		}
+		if err := k8sClient.Delete(ctx, cm); err != nil {
+			t.Fatal(err)
+		}
+		k8sClient.ArchiveSettings.SigningKey = key
+
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "ops", Name: name}, secret); err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	config := func(resourceType, namespace string) *cronschedulesv1.CleanupConfig {
+		return &cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{resourceType},
+			Namespaces:    []string{namespace},
+			Archive:       &cronschedulesv1.CleanupArchive{},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		signingKey []byte
+		tamper     func(secret *corev1.Secret)
+		config     *cronschedulesv1.CleanupConfig
+		wantErr    string
+	}{
+		{name: "signed archive", signingKey: key, config: config("ConfigMap", "default")},
+		{name: "unsigned archive", config: config("ConfigMap", "default"), wantErr: "no valid signature"},
+		{name: "signed with another key", signingKey: []byte("other-key"), config: config("ConfigMap", "default"), wantErr: "no valid signature"},
+		{name: "tampered data", signingKey: key, config: config("ConfigMap", "default"), wantErr: "no valid signature",
+			tamper: func(secret *corev1.Secret) {
+				data, _ := gzipBytes([]byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: forged\n  namespace: default\n"))
+				secret.Data[archiveDataKey] = data
+			}},
+		{name: "kind not cleaned up", signingKey: key, config: config("Secret", "default"), wantErr: "not a kind"},
+		{name: "namespace not cleaned up", signingKey: key, config: config("ConfigMap", "other"), wantErr: "not in a namespace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := newOrphanTestClient()
+			secret := archive(k8sClient, tt.signingKey)
+			if tt.tamper != nil {
+				tt.tamper(secret)
+				if err := k8sClient.Update(ctx, secret); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := k8sClient.RestoreArchive(ctx, tt.config, owner, secret.Name)
+			if tt.wantErr == "" {
+				if err != nil || result.Restored != 1 {
+					t.Errorf("RestoreArchive() = %+v, %v", result, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || result.Restored != 0 {
+				t.Errorf("RestoreArchive() = %+v, %v, want error containing %q", result, err, tt.wantErr)
+			}
+		})
+	}
+
+	k8sClient := newOrphanTestClient()
+	secret := archive(k8sClient, key)
+	k8sClient.ArchiveSettings.SigningKey = nil
+	if _, err := k8sClient.RestoreArchive(ctx, config("ConfigMap", "default"), owner, secret.Name); err == nil {
+		t.Error("expected restores to be refused without a signing key")
+	}
+}
+
+func TestPlanCleanupSkipsArchives(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := metav1.NewTime(time.Now().Add(-10 * Day))
+	meta := func(name string, archive bool) metav1.ObjectMeta {
+		objectMeta := metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "archives",
+			CreationTimestamp: old,
+			Annotations:       map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"},
+		}
+		if archive {
+			objectMeta.Labels = map[string]string{ArchiveLabel: "true"}
+		}
+		return objectMeta
+	}
+
+	tests := []struct {
+		name   string
+		config cronschedulesv1.CleanupConfig
+	}{
+		{name: "annotation", config: cronschedulesv1.CleanupConfig{}},
+		{name: "age orphans", config: cronschedulesv1.CleanupConfig{
+			AnnotationKey:          "unused",
+			CleanupOrphanResources: true,
+			OrphanResourceMaxAge:   "1d",
+		}},
+		{name: "unreferenced orphans", config: cronschedulesv1.CleanupConfig{
+			AnnotationKey:          "unused",
+			CleanupOrphanResources: true,
+			OrphanResourceMaxAge:   "1d",
+			OrphanDetection:        cronschedulesv1.OrphanDetectionUnreferenced,
+		}},
+		{name: "unreferenced configs pass", config: cronschedulesv1.CleanupConfig{
+			AnnotationKey:       "unused",
+			UnreferencedConfigs: &cronschedulesv1.UnreferencedConfigsCleanup{Kinds: []string{"ConfigMap", "Secret"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := newOrphanTestClient(
+				&corev1.Secret{ObjectMeta: meta("nightly-archive-20250701-120000", true)},
+				&corev1.ConfigMap{ObjectMeta: meta("nightly-archive-20250702-120000", true)},
+				&corev1.ConfigMap{ObjectMeta: meta("stale", false)},
+			)
+			cleanupConfig := tt.config
+			if cleanupConfig.AnnotationKey == "" {
+				cleanupConfig.AnnotationKey = "cleanup-after"
+			}
+			cleanupConfig.ResourceTypes = []string{"ConfigMap", "Secret"}
+			cleanupConfig.Namespaces = []string{"archives"}
+
+			plan, err := k8sClient.PlanCleanup(ctx, &cleanupConfig, "archives", nil)
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+			var planned []string
+			for _, obj := range plan.Deletions {
+				planned = append(planned, obj.GetName())
+			}
+			if len(planned) != 1 || planned[0] != "stale" {
+				t.Errorf("planned deletions = %v, want only stale", planned)
+			}
+			archives := 0
+			for _, skipped := range plan.Skipped {
+				if skipped.Reason == "cleanup archive" {
+					archives++
+				}
+			}
+			if archives != 2 {
+				t.Errorf("expected both archives to be skipped as cleanup archives, got %v", plan.Skipped)
+			}
+		})
+	}
+}
+
+func TestPruneArchives(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	now := time.Now()
+	owner := types.NamespacedName{Namespace: "ops", Name: "nightly"}
+
+	archiveSecret := func(name, ownerName string, age time.Duration) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ops",
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			Labels:            map[string]string{ArchiveLabel: "true"},
+			Annotations:       map[string]string{archiveOwnerAnnotation: "ops/" + ownerName},
+		}}
+	}
+	k8sClient := newOrphanTestClient(
+		archiveSecret("expired", "nightly", 10*Day),
+		archiveSecret("recent", "nightly", 2*Day),
+		archiveSecret("other-owner", "other", 10*Day),
+	)
+
+	pruned, err := k8sClient.PruneArchives(ctx, &cronschedulesv1.CleanupArchive{Retention: "1w"}, owner, now)
+	if err != nil || pruned != 1 {
+		t.Fatalf("PruneArchives() = %d, %v, want 1", pruned, err)
+	}
+	for name, wantExists := range map[string]bool{"expired": false, "recent": true, "other-owner": true} {
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "ops", Name: name}, &corev1.Secret{})
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("%s exists = %v, want %v", name, exists, wantExists)
+		}
+	}
+
+	dir := t.TempDir()
+	k8sClient.ArchiveSettings.Root = dir
+	archive := &cronschedulesv1.CleanupArchive{Sink: cronschedulesv1.ArchiveSinkDirectory, Retention: "7d"}
+	filePath := func(name string) string {
+		path, err := k8sClient.archiveFilePath(archive, owner, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	old := ArchiveName("nightly", now.Add(-10*Day))
+	for _, name := range []string{old, ArchiveName("nightly", now), ArchiveName("other", now.Add(-10*Day))} {
+		if err := k8sClient.writeArchiveFile(filePath(name), owner, name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldTime := now.Add(-10 * Day)
+	for _, name := range []string{old, ArchiveName("other", oldTime)} {
+		if err := os.Chtimes(filePath(name), oldTime, oldTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pruned, err = k8sClient.PruneArchives(ctx, archive, owner, now)
+	if err != nil || pruned != 1 {
+		t.Fatalf("PruneArchives() directory = %d, %v, want 1", pruned, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ops", old+archiveFileSuffix)); !os.IsNotExist(err) {
+		t.Errorf("expired archive file still exists")
+	}
+}
+
+func TestValidateArchivePath(t *testing.T) {
+	for path, wantErr := range map[string]bool{
+		"":                false,
+		"nightly":         false,
+		"teams/ops":       false,
+		"/var/archives":   true,
+		"../etc":          true,
+		"teams/../../etc": true,
+	} {
+		if err := ValidateArchivePath(path); (err != nil) != wantErr {
+			t.Errorf("ValidateArchivePath(%q) error = %v, wantErr %v", path, err, wantErr)
+		}
+	}
+}
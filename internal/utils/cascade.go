@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// instanceLabel identifies the objects of an application instance
+const instanceLabel = "app.kubernetes.io/instance"
+
+// cascadeKinds are the kinds of related objects cascade cleanup looks for, in the order they are found;
+// Services come before Ingresses, which are related through them
+var cascadeKinds = []string{"Service", "Ingress", "ConfigMap", "Secret", "HorizontalPodAutoscaler", "PodDisruptionBudget"}
+
+// IsCascadeKind reports whether cascade cleanup can delete related objects of the kind
+func IsCascadeKind(kind string) bool {
+	return slices.Contains(cascadeKinds, kind)
+}
+
+// podTemplate returns the pod template of a workload cascade cleanup applies to, or nil for other objects
+func podTemplate(obj client.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	case *batchv1.Job:
+		return &o.Spec.Template
+	}
+	return nil
+}
+
+// planCascade plans the deletion of a workload together with its related objects. The whole group is
+// kept when one of the related objects is protected or they can't be listed.
+func (c *K8sClient) planCascade(ctx context.Context, obj client.Object, reason string, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) {
+	template := podTemplate(obj)
+	if template == nil {
+		plan.add(obj, reason)
+		return
+	}
+
+	related, err := c.relatedObjects(ctx, obj, template, cleanupConfig.Cascade)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to find related objects, keeping workload", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		plan.skip(objectKind(obj), obj, "related objects could not be listed")
+		return
+	}
+	if protected := plan.protectedMember(related); protected != "" {
+		plan.skip(objectKind(obj), obj, protected)
+		return
+	}
+	plan.addGroup(obj, reason, &cleanupGroup{
+		name:         objectKind(obj) + "/" + obj.GetName(),
+		failureEvent: "CascadeCleanupFailed",
+		related:      related,
+	})
+}
+
+// relatedObjects returns the objects of the cascade kinds related to a workload, listed from the API server
+// like the other workloads they are checked against
+func (c *K8sClient) relatedObjects(ctx context.Context, workload client.Object, template *corev1.PodTemplateSpec, cascade *cronschedulesv1.CascadeCleanup) ([]client.Object, error) {
+	kinds := cascade.Kinds
+	if len(kinds) == 0 {
+		kinds = cascadeKinds
+	}
+
+	instance := workload.GetLabels()[instanceLabel]
+	if instance == "" {
+		instance = template.Labels[instanceLabel]
+	}
+	podLabels := labels.Set(template.Labels)
+
+	referenced := &namespaceReferences{configMaps: map[string]string{}, secrets: map[string]string{}}
+	referenced.addPodSpec(&template.Spec, "")
+	others, err := c.otherWorkloads(ctx, workload)
+	if err != nil {
+		return nil, err
+	}
+
+	var related []client.Object
+	services := map[string]bool{}
+	for _, kind := range cascadeKinds {
+		if !slices.Contains(kinds, kind) {
+			continue
+		}
+		objList, err := c.createResourceList(kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.uncachedReader().List(ctx, objList, client.InNamespace(workload.GetNamespace())); err != nil {
+			return nil, fmt.Errorf("failed to list %s in namespace %s: %w", kind, workload.GetNamespace(), err)
+		}
+
+		for _, item := range c.extractItemsFromList(objList) {
+			matched := instance != "" && item.GetLabels()[instanceLabel] == instance
+			switch o := item.(type) {
+			case *corev1.Service:
+				selector := labels.SelectorFromSet(o.Spec.Selector)
+				if len(o.Spec.Selector) > 0 {
+					matched = (matched || selector.Matches(podLabels)) && !others.selects(selector)
+				}
+				if matched {
+					services[o.Name] = true
+				}
+			case *networkingv1.Ingress:
+				routed, routedElsewhere := ingressRoutes(o, services)
+				matched = (matched || routed) && !routedElsewhere
+			case *corev1.ConfigMap:
+				_, sharedRef := others.references.configMaps[o.Name]
+				_, ref := referenced.configMaps[o.Name]
+				matched = (matched || ref) && !sharedRef
+			case *corev1.Secret:
+				_, sharedRef := others.references.secrets[o.Name]
+				_, ref := referenced.secrets[o.Name]
+				matched = (matched || ref) && !sharedRef
+			case *autoscalingv2.HorizontalPodAutoscaler:
+				// An HPA scales a single workload, so one scaling another workload is never related
+				target := o.Spec.ScaleTargetRef
+				matched = target.Kind == objectKind(workload) && target.Name == workload.GetName()
+			case *policyv1.PodDisruptionBudget:
+				selector, err := metav1.LabelSelectorAsSelector(o.Spec.Selector)
+				if err == nil && !selector.Empty() {
+					matched = (matched || selector.Matches(podLabels)) && !others.selects(selector)
+				}
+			}
+			if matched {
+				related = append(related, item)
+			}
+		}
+	}
+	return related, nil
+}
+
+// ingressRoutes reports whether the Ingress sends traffic to one of the services, and whether it also sends
+// traffic to other services or resource backends. An Ingress that routes elsewhere is shared and kept.
+func ingressRoutes(ingress *networkingv1.Ingress, services map[string]bool) (routed, routedElsewhere bool) {
+	check := func(backend networkingv1.IngressBackend) {
+		if backend.Service != nil && services[backend.Service.Name] {
+			routed = true
+		} else {
+			routedElsewhere = true
+		}
+	}
+	if ingress.Spec.DefaultBackend != nil {
+		check(*ingress.Spec.DefaultBackend)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			check(path.Backend)
+		}
+	}
+	return routed, routedElsewhere
+}
+
+// workloadSet describes the workloads in a namespace other than the one cascade cleanup deletes: the ConfigMaps
+// and Secrets their pod templates reference and the labels of their pods. Objects they use are never deleted
+// with the workload.
+type workloadSet struct {
+	references *namespaceReferences
+	podLabels  []labels.Set
+}
+
+// selects reports whether the selector matches the pods of one of the workloads
+func (w *workloadSet) selects(selector labels.Selector) bool {
+	return slices.ContainsFunc(w.podLabels, func(podLabels labels.Set) bool { return selector.Matches(podLabels) })
+}
+
+// otherWorkloads returns the Deployments, StatefulSets, DaemonSets, Jobs and CronJobs in the namespace other
+// than workload. They are listed from the API server: a workload missing from a stale cache would let the
+// objects it shares be deleted with the group.
+func (c *K8sClient) otherWorkloads(ctx context.Context, workload client.Object) (*workloadSet, error) {
+	reader := c.uncachedReader()
+	refs := &namespaceReferences{configMaps: map[string]string{}, secrets: map[string]string{}}
+	others := &workloadSet{references: refs}
+	inNamespace := client.InNamespace(workload.GetNamespace())
+	self := objectKind(workload) + "/" + workload.GetName()
+
+	add := func(template *corev1.PodTemplateSpec, referrer string) {
+		if referrer != self {
+			refs.addPodSpec(&template.Spec, referrer)
+			others.podLabels = append(others.podLabels, labels.Set(template.Labels))
+		}
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := reader.List(ctx, deployments, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		add(&deployments.Items[i].Spec.Template, "Deployment/"+deployments.Items[i].Name)
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := reader.List(ctx, statefulSets, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		add(&statefulSets.Items[i].Spec.Template, "StatefulSet/"+statefulSets.Items[i].Name)
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := reader.List(ctx, daemonSets, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		add(&daemonSets.Items[i].Spec.Template, "DaemonSet/"+daemonSets.Items[i].Name)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := reader.List(ctx, jobs, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		add(&jobs.Items[i].Spec.Template, "Job/"+jobs.Items[i].Name)
+	}
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := reader.List(ctx, cronJobs, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		add(&cronJobs.Items[i].Spec.JobTemplate.Spec.Template, "CronJob/"+cronJobs.Items[i].Name)
+	}
+	return others, nil
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCascadeCleanup(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}
+	}
+	workload := func(name string, annotations map[string]string, configMap, secret string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{ObjectMeta: meta(name, map[string]string{instanceLabel: name})}
+		deployment.Annotations = annotations
+		deployment.Spec.Template.Labels = map[string]string{"app": name, "tier": "web"}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "app",
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secret}}},
+			},
+		}}
+		return deployment
+	}
+	pathType := networkingv1.PathTypePrefix
+
+	objects := func() []client.Object {
+		return []client.Object{
+			workload("preview", map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"}, "preview-config", "shared-secret"),
+			workload("main", nil, "main-config", "shared-secret"),
+			&corev1.Service{ObjectMeta: meta("preview", nil), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "preview"}}},
+			&corev1.Service{ObjectMeta: meta("main", nil), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "main"}}},
+			&networkingv1.Ingress{ObjectMeta: meta("preview", nil), Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+					Path: "/", PathType: &pathType,
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "preview"}},
+				}}}},
+			}}}},
+			&corev1.ConfigMap{ObjectMeta: meta("preview-config", nil)},
+			&corev1.ConfigMap{ObjectMeta: meta("preview-values", map[string]string{instanceLabel: "preview"})},
+			&corev1.ConfigMap{ObjectMeta: meta("main-config", nil)},
+			&corev1.Secret{ObjectMeta: meta("shared-secret", nil)},
+			&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: meta("preview", nil), Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "preview"},
+			}},
+			&policyv1.PodDisruptionBudget{ObjectMeta: meta("preview", nil), Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "preview"}},
+			}},
+		}
+	}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{"Deployment"},
+		Cascade:       &cronschedulesv1.CascadeCleanup{},
+	}
+	recordNames := func(records []cronschedulesv1.CleanupRecord, action string) []string {
+		var names []string
+		for _, record := range records {
+			if record.Action == action {
+				names = append(names, record.Kind+"/"+record.Name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("deletes the group", func(t *testing.T) {
+		k8sClient := newOrphanTestClient(objects()...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+
+		want := []string{
+			"ConfigMap/preview-config", "ConfigMap/preview-values", "Deployment/preview", "HorizontalPodAutoscaler/preview",
+			"Ingress/preview", "PodDisruptionBudget/preview", "Service/preview",
+		}
+		if got := recordNames(result.Records, cronschedulesv1.CleanupActionDeleted); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("deleted %v, want %v", got, want)
+		}
+		for _, record := range result.Records {
+			if record.Group != "Deployment/preview" {
+				t.Errorf("record %s/%s has group %q", record.Kind, record.Name, record.Group)
+			}
+		}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "shared-secret"}, &corev1.Secret{}); err != nil {
+			t.Errorf("secret shared with another workload should be kept, got %v", err)
+		}
+	})
+
+	t.Run("keeps objects other workloads use", func(t *testing.T) {
+		web := map[string]string{"tier": "web"}
+		backend := func(service string) networkingv1.HTTPIngressPath {
+			return networkingv1.HTTPIngressPath{Path: "/" + service, PathType: &pathType,
+				Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service}}}
+		}
+		objs := append(objects(),
+			&corev1.Service{ObjectMeta: meta("web", map[string]string{instanceLabel: "preview"}), Spec: corev1.ServiceSpec{Selector: web}},
+			&networkingv1.Ingress{ObjectMeta: meta("shared", nil), Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+					backend("preview"), backend("main"),
+				}}},
+			}}}},
+			&policyv1.PodDisruptionBudget{ObjectMeta: meta("web", nil), Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: web},
+			}},
+			&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: meta("main", map[string]string{instanceLabel: "preview"}), Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "main"},
+			}},
+		)
+		k8sClient := newOrphanTestClient(objs...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+
+		for _, name := range recordNames(result.Records, cronschedulesv1.CleanupActionDeleted) {
+			switch name {
+			case "Service/web", "Ingress/shared", "PodDisruptionBudget/web", "HorizontalPodAutoscaler/main":
+				t.Errorf("%s is used by another workload and should be kept", name)
+			}
+		}
+		if result.Deleted != 7 {
+			t.Errorf("deleted = %d, want the 7 objects of the group", result.Deleted)
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		k8sClient := newOrphanTestClient(objects()...)
+		k8sClient.Client = interceptor.NewClient(k8sClient.Client.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if _, ok := obj.(*networkingv1.Ingress); ok {
+					return errors.New("admission webhook denied the request")
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		})
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+
+		if got := recordNames(result.Records, cronschedulesv1.CleanupActionDeleted); len(got) != 1 || got[0] != "Service/preview" {
+			t.Errorf("deleted %v, want only the Service deleted before the failure", got)
+		}
+		if result.Failed != 6 {
+			t.Errorf("failed = %d, want the Ingress and the 5 objects after it", result.Failed)
+		}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "preview"}, &appsv1.Deployment{})
+		if apierrors.IsNotFound(err) {
+			t.Error("workload should be kept when its group fails")
+		}
+	})
+
+	t.Run("keeps the group of a protected object", func(t *testing.T) {
+		objs := objects()
+		objs = append(objs, &corev1.Secret{ObjectMeta: meta("preview-db", map[string]string{instanceLabel: "preview", ProtectedKey: "true"})})
+		k8sClient := newOrphanTestClient(objs...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 0 || len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0].Reason, "preview-db") {
+			t.Errorf("result = %+v, want the group kept", result)
+		}
+	})
+}
@@ -0,0 +1,350 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// maxSkippedResources bounds the skipped resources reported by a cleanup run
+const maxSkippedResources = 50
+
+// CleanupPlan lists the resources a cleanup run is about to delete
+type CleanupPlan struct {
+	// Deletions are the resources due for cleanup, in deletion order
+	Deletions []client.Object
+	// Matched is the number of resources considered, i.e. matching the resource types and selectors
+	Matched int
+	// Skipped lists resources considered for cleanup but kept, with the reason, up to maxSkippedResources
+	Skipped []cronschedulesv1.SkippedResource
+	// Quarantines are the resources due for cleanup that are quarantined instead of deleted
+	Quarantines []client.Object
+	// Rescues are the resources whose quarantine label was removed, to be restored
+	Rescues []client.Object
+	// Quarantined lists the resources in quarantine once the plan is executed, up to maxSkippedResources
+	Quarantined []cronschedulesv1.QuarantinedResource
+	// Upcoming are the resources due for cleanup within the warnBefore window, to be notified
+	Upcoming []pendingDeletion
+	// Withdrawn are the notified resources no longer due for cleanup within the window
+	Withdrawn []client.Object
+	// Continuation is where a paged run stopped for this reconcile, nil when it listed everything
+	Continuation *cronschedulesv1.CleanupContinuation
+
+	planned map[string]bool
+	// reasons holds why each planned resource is cleaned up
+	reasons map[client.Object]string
+	// groups maps the resources deleted together with related objects to their group, and groupOf the related
+	// objects to their primary resource
+	groups     map[client.Object]*cleanupGroup
+	groupOf    map[client.Object]client.Object
+	protection *protection
+	filter     *ResourceFilter
+	// namespaces are the namespaces the run cleans up resource by resource
+	namespaces []string
+
+	// quarantinePeriod enables two-phase cleanup when positive
+	quarantinePeriod time.Duration
+	// rescuePeriod is how long rescued resources are not quarantined again
+	rescuePeriod time.Duration
+	// warnBefore enables advance notices of upcoming deletions when positive
+	warnBefore time.Duration
+	// dates resolves the dates and times of cleanup annotations
+	dates cleanupDates
+
+	// pageSize enables paged listing when positive, stopping the run after maxPages pages, or once maxDeletions
+	// deletions are planned, when positive
+	pageSize     int64
+	maxPages     int
+	maxDeletions int
+	pages        int
+	// priorPlanned and priorMatched are the totals of the earlier parts of a resumed run
+	priorPlanned int
+	priorMatched int
+	// acknowledged is the number of planned deletions acknowledged for the run, 0 if it wasn't acknowledged
+	acknowledged int
+	// resume is the continuation of the interrupted run being resumed, until its listing is reached, and
+	// continueToken its token for planPages
+	resume        *cronschedulesv1.CleanupContinuation
+	continueToken string
+	// startedAt is when the run, or the interrupted run it resumes, started
+	startedAt time.Time
+	now       time.Time
+	// expired counts the deletions of resources at the end of their quarantine
+	expired int
+}
+
+// add plans the deletion of obj for the reason, once, unless it is protected. In two-phase cleanup, obj is
+// quarantined first and only deleted once its quarantine is over. It reports whether obj was kept.
+func (p *CleanupPlan) add(obj client.Object, reason string) (kept bool) {
+	kind := objectKind(obj)
+	if p.protection != nil {
+		var protected string
+		if kind, protected = p.protection.reason(obj); protected != "" {
+			p.skip(kind, obj, protected)
+			return true
+		}
+	}
+	if p.reasons == nil {
+		p.reasons = map[client.Object]string{}
+	}
+
+	if p.quarantinePeriod > 0 {
+		state, until := quarantineStateOf(obj)
+		if state == rescued && (until.IsZero() || !p.now.Before(until.Add(p.rescuePeriod))) {
+			// The rescue has expired, so the resource can be quarantined again
+			state = notQuarantined
+		}
+		switch state {
+		case rescued:
+			p.skip(kind, obj, "rescued from quarantine")
+			return true
+		case released:
+			p.rescue(obj)
+			return true
+		case quarantined:
+			if until.IsZero() || p.now.Before(until) {
+				p.recordQuarantined(kind, obj, until)
+				return false
+			}
+			if p.once("delete", obj) {
+				p.expired++
+				p.reasons[obj] = reason
+				p.Deletions = append(p.Deletions, obj)
+			}
+			return false
+		default:
+			if p.once("quarantine", obj) {
+				p.reasons[obj] = reason
+				p.Quarantines = append(p.Quarantines, obj)
+				p.recordQuarantined(kind, obj, p.now.Add(p.quarantinePeriod))
+			}
+			return false
+		}
+	}
+
+	if p.once("delete", obj) {
+		p.reasons[obj] = reason
+		p.Deletions = append(p.Deletions, obj)
+	}
+	return false
+}
+
+// cleanupGroup is a set of related objects deleted together with a primary resource
+type cleanupGroup struct {
+	// name identifies the group in the cleanup records
+	name string
+	// failureEvent is the reason of the event recorded on the primary resource when the group can't be deleted
+	failureEvent string
+	related      []client.Object
+}
+
+// addGroup plans the deletion of a primary resource with its related objects as one group: the related objects
+// are deleted first, and the primary resource only once they all are. The related objects are kept with it.
+func (p *CleanupPlan) addGroup(primary client.Object, reason string, group *cleanupGroup) {
+	deletions := len(p.Deletions)
+	if p.add(primary, reason) {
+		return
+	}
+	grouped := len(p.Deletions) > deletions
+
+	related := group.related
+	group.related = nil
+	for _, obj := range related {
+		p.Matched++
+		deletions = len(p.Deletions)
+		p.add(obj, cronschedulesv1.CleanupReasonCascade)
+		if !grouped || len(p.Deletions) == deletions {
+			continue
+		}
+		if p.groups == nil {
+			p.groups = map[client.Object]*cleanupGroup{}
+			p.groupOf = map[client.Object]client.Object{}
+		}
+		p.groups[primary] = group
+		group.related = append(group.related, obj)
+		p.groupOf[obj] = primary
+	}
+}
+
+// protectedMember returns why a group is kept when one of its related objects is protected, or an empty string
+func (p *CleanupPlan) protectedMember(related []client.Object) string {
+	if p.protection == nil {
+		return ""
+	}
+	for _, obj := range related {
+		if kind, protected := p.protection.reason(obj); protected != "" {
+			return fmt.Sprintf("related %s %s is protected: %s", kind, obj.GetName(), protected)
+		}
+	}
+	return ""
+}
+
+// rescue plans the restoration of a resource whose quarantine label was removed
+func (p *CleanupPlan) rescue(obj client.Object) {
+	if p.once("rescue", obj) {
+		p.Rescues = append(p.Rescues, obj)
+	}
+}
+
+// once reports whether the action is planned for obj for the first time
+func (p *CleanupPlan) once(action string, obj client.Object) bool {
+	key := fmt.Sprintf("%s/%T/%s/%s/%s", action, obj, obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+	if p.planned[key] {
+		return false
+	}
+	if p.planned == nil {
+		p.planned = map[string]bool{}
+	}
+	p.planned[key] = true
+	return true
+}
+
+// recordQuarantined records a resource in quarantine until the deadline
+func (p *CleanupPlan) recordQuarantined(kind string, obj client.Object, until time.Time) {
+	if len(p.Quarantined) >= maxSkippedResources {
+		return
+	}
+	p.Quarantined = append(p.Quarantined, cronschedulesv1.QuarantinedResource{
+		Kind:        kind,
+		Namespace:   obj.GetNamespace(),
+		Name:        obj.GetName(),
+		DeleteAfter: metav1.NewTime(until),
+	})
+}
+
+// objectKind returns the kind of obj from its type meta, or its Go type when unset
+func objectKind(obj client.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
+
+// skip records a resource kept by the cleanup
+func (p *CleanupPlan) skip(kind string, obj client.Object, reason string) {
+	if len(p.Skipped) >= maxSkippedResources {
+		return
+	}
+	p.Skipped = append(p.Skipped, cronschedulesv1.SkippedResource{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Reason:    reason,
+	})
+}
+
+// withdraw removes a planned deletion from the plan and keeps obj for the reason. A group is deleted as a whole
+// or not at all, so the rest of obj's group is kept with it.
+func (p *CleanupPlan) withdraw(obj client.Object, reason string) {
+	primary := obj
+	if owner, ok := p.groupOf[obj]; ok {
+		primary = owner
+	}
+	members := []client.Object{obj}
+	if group, ok := p.groups[primary]; ok {
+		members = append([]client.Object{primary}, group.related...)
+		for _, related := range group.related {
+			delete(p.groupOf, related)
+		}
+		delete(p.groups, primary)
+	}
+
+	for _, member := range members {
+		index := slices.Index(p.Deletions, member)
+		if index < 0 {
+			continue
+		}
+		p.Deletions = slices.Delete(p.Deletions, index, index+1)
+		delete(p.reasons, member)
+		memberReason := reason
+		if member != obj {
+			memberReason = fmt.Sprintf("%s %s is %s", objectKind(obj), obj.GetName(), reason)
+		}
+		p.skip(objectKind(member), member, memberReason)
+	}
+}
+
+// CleanupBlockedError is returned when a cleanup run would delete more than the configured limits allow
+type CleanupBlockedError struct {
+	Planned int
+	Matched int
+	Reason  string
+}
+
+func (e *CleanupBlockedError) Error() string {
+	return fmt.Sprintf("cleanup blocked: %s", e.Reason)
+}
+
+// Totals returns the deletions planned and the resources matched by the run, including the earlier parts of
+// a resumed paged run. Resources are counted when they are quarantined, not again when deleted at the end of
+// their quarantine.
+func (p *CleanupPlan) Totals() (planned, matched int) {
+	return p.priorPlanned + len(p.Deletions) - p.expired + len(p.Quarantines), p.priorMatched + p.Matched
+}
+
+// Acknowledged returns the number of planned deletions acknowledged for the run by an earlier part, 0 if none
+func (p *CleanupPlan) Acknowledged() int {
+	return p.acknowledged
+}
+
+// Acknowledge records that the run was acknowledged with its planned deletions, so that its later parts
+// carry the acknowledgment in their continuation
+func (p *CleanupPlan) Acknowledge(planned int) {
+	p.acknowledged = planned
+	if p.Continuation != nil {
+		p.Continuation.Acknowledged = int32(planned)
+	}
+}
+
+// CheckLimits returns a *CleanupBlockedError when the run exceeds maxDeletionsPerRun or maxDeletionPercent.
+// A paged run is checked with the totals of all its parts so far.
+func (p *CleanupPlan) CheckLimits(cleanupConfig *cronschedulesv1.CleanupConfig) error {
+	planned, matched := p.Totals()
+
+	if limit := cleanupConfig.MaxDeletionsPerRun; limit > 0 && planned > int(limit) {
+		return &CleanupBlockedError{
+			Planned: planned,
+			Matched: matched,
+			Reason:  fmt.Sprintf("%d resources planned for deletion exceed maxDeletionsPerRun (%d)", planned, limit),
+		}
+	}
+
+	if limit := cleanupConfig.MaxDeletionPercent; limit > 0 && matched > 0 && planned*100 > int(limit)*matched {
+		return &CleanupBlockedError{
+			Planned: planned,
+			Matched: matched,
+			Reason: fmt.Sprintf("%d of %d matched resources (%d%%) planned for deletion exceed maxDeletionPercent (%d%%)",
+				planned, matched, planned*100/matched, limit),
+		}
+	}
+	return nil
+}
+
+// CleanupResult summarizes a cleanup run
+type CleanupResult struct {
+	// Deleted is the number of resources deleted (or that would be deleted in dry-run mode)
+	Deleted int32
+	// Skipped lists resources considered for cleanup but kept, with the reason, up to maxSkippedResources
+	Skipped []cronschedulesv1.SkippedResource
+	// Quarantined is the number of resources quarantined (or that would be quarantined in dry-run mode)
+	Quarantined int32
+	// QuarantinedResources lists the resources in quarantine, up to maxSkippedResources
+	QuarantinedResources []cronschedulesv1.QuarantinedResource
+	// UpcomingDeletions lists the resources due for cleanup within the warnBefore window, up to maxSkippedResources
+	UpcomingDeletions []cronschedulesv1.UpcomingDeletion
+	// Failed is the number of resources that could not be deleted or quarantined
+	Failed int32
+	// Records lists what the run did to each resource, up to maxSkippedResources
+	Records []cronschedulesv1.CleanupRecord
+	// Truncated is the number of resources left out of Records
+	Truncated int32
+	// PendingDeletions lists the resources deleted with waitForDeletion that still exist, up to maxSkippedResources
+	PendingDeletions []cronschedulesv1.PendingDeletion
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCleanupPlanCheckLimits(t *testing.T) {
+	plan := &CleanupPlan{Matched: 10}
+	for i := range 4 {
+		plan.add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: "default"}}, cronschedulesv1.CleanupReasonAnnotation)
+	}
+	// Planning the same object twice deletes it once
+	plan.add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm-0", Namespace: "default"}}, cronschedulesv1.CleanupReasonAnnotation)
+
+	tests := []struct {
+		name        string
+		maxDeletion int32
+		maxPercent  int32
+		wantBlocked bool
+	}{
+		{name: "no limits"},
+		{name: "below max deletions", maxDeletion: 4},
+		{name: "above max deletions", maxDeletion: 3, wantBlocked: true},
+		{name: "below max percent", maxPercent: 40},
+		{name: "above max percent", maxPercent: 30, wantBlocked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := plan.CheckLimits(&cronschedulesv1.CleanupConfig{MaxDeletionsPerRun: tt.maxDeletion, MaxDeletionPercent: tt.maxPercent})
+			var blocked *CleanupBlockedError
+			if got := errors.As(err, &blocked); got != tt.wantBlocked {
+				t.Fatalf("CheckLimits() = %v, want blocked %v", err, tt.wantBlocked)
+			}
+			if blocked != nil && (blocked.Planned != 4 || blocked.Matched != 10) {
+				t.Errorf("unexpected blocked counts: %+v", blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+//+kubebuilder:rbac:groups=cronschedules.elbazi.co,resources=cleanuppolicies,verbs=get;list;watch
+
+const (
+	// ProtectedKey as a label or annotation set to "true" protects a resource from cleanup
+	ProtectedKey = "cronjob-scale-down-operator/protected"
+	// ProtectedLabel set to "true" also protects a resource. Unlike ProtectedKey it is not prefixed, because
+	// teams already use protected=true to mark objects that must never be deleted by automation.
+	ProtectedLabel = "protected"
+
+	// systemNamespace is always protected
+	systemNamespace = "kube-system"
+	// bootstrappingLabel marks the RBAC objects Kubernetes creates and reconciles itself
+	bootstrappingLabel = "kubernetes.io/bootstrapping"
+)
+
+// protection decides which resources cleanup must never delete: the built-in protections and
+// the cluster-wide CleanupPolicies, which take precedence over every CronJobScaleDown
+type protection struct {
+	policies []cronschedulesv1.CleanupPolicy
+	gvkFor   func(obj client.Object) (schema.GroupVersionKind, error)
+}
+
+// loadProtection reads the CleanupPolicies. Cleanup must not run when they can't be read.
+func (c *K8sClient) loadProtection(ctx context.Context) (*protection, error) {
+	logger := log.FromContext(ctx)
+	p := &protection{gvkFor: func(obj client.Object) (schema.GroupVersionKind, error) {
+		return c.GroupVersionKindFor(obj)
+	}}
+
+	policies := &cronschedulesv1.CleanupPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		if meta.IsNoMatchError(err) {
+			// The CleanupPolicy CRD isn't installed, so there are no policies
+			logger.Info("CleanupPolicy CRD not installed, applying built-in protections only")
+			return p, nil
+		}
+		return nil, fmt.Errorf("failed to list cleanup policies: %w", err)
+	}
+
+	for _, policy := range policies.Items {
+		for _, pattern := range policy.Spec.ProtectedNamePatterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid name pattern %q in CleanupPolicy %s: %w", pattern, policy.Name, err)
+			}
+		}
+	}
+	p.policies = policies.Items
+	return p, nil
+}
+
+// namespaceReason returns why no resource in the namespace may be cleaned up, or an empty string
+func (p *protection) namespaceReason(namespace string) string {
+	if namespace == systemNamespace {
+		return systemNamespace + " is always protected"
+	}
+	for _, policy := range p.policies {
+		if slices.Contains(policy.Spec.ProtectedNamespaces, namespace) {
+			return fmt.Sprintf("namespace %s protected by CleanupPolicy %s", namespace, policy.Name)
+		}
+	}
+	return ""
+}
+
+// reason returns the kind of obj and why it is protected, or an empty reason if it may be cleaned up
+func (p *protection) reason(obj client.Object) (string, string) {
+	gvk, err := p.gvkFor(obj)
+	if err != nil {
+		// Without its kind the kind rules can't be checked, so keep the resource
+		return fmt.Sprintf("%T", obj), fmt.Sprintf("unknown kind: %v", err)
+	}
+	kind := gvk.Kind
+
+	if obj.GetLabels()[ProtectedKey] == "true" || obj.GetAnnotations()[ProtectedKey] == "true" {
+		return kind, "marked with " + ProtectedKey
+	}
+	if obj.GetLabels()[ProtectedLabel] == "true" {
+		return kind, "labeled " + ProtectedLabel + "=true"
+	}
+	if obj.GetLabels()[ArchiveLabel] == "true" && gvk.Group == "" && (kind == "Secret" || kind == "ConfigMap") {
+		// Archives are only removed by their retention
+		return kind, "cleanup archive"
+	}
+	if gvk.Group == rbacv1.GroupName {
+		if _, ok := obj.GetLabels()[bootstrappingLabel]; ok || strings.HasPrefix(obj.GetName(), "system:") {
+			return kind, "Kubernetes bootstrap RBAC"
+		}
+	}
+
+	namespace := obj.GetNamespace()
+	if kind == "Namespace" && gvk.Group == "" {
+		namespace = obj.GetName()
+	}
+	if namespace != "" {
+		if reason := p.namespaceReason(namespace); reason != "" {
+			return kind, reason
+		}
+	}
+
+	for _, policy := range p.policies {
+		if reason := policyReason(&policy.Spec, obj, gvk); reason != "" {
+			return kind, fmt.Sprintf("%s protected by CleanupPolicy %s", reason, policy.Name)
+		}
+	}
+	return kind, ""
+}
+
+// policyReason returns which rule of the policy protects obj, or an empty string
+func policyReason(spec *cronschedulesv1.CleanupPolicySpec, obj client.Object, gvk schema.GroupVersionKind) string {
+	for _, entry := range spec.ProtectedKinds {
+		if matchesKind(entry, gvk) {
+			return "kind " + entry
+		}
+	}
+	for _, pattern := range spec.ProtectedNamePatterns {
+		if matched, _ := path.Match(pattern, obj.GetName()); matched {
+			return "name pattern " + pattern
+		}
+	}
+	for key, value := range spec.ProtectedLabels {
+		if v, ok := obj.GetLabels()[key]; ok && v == value {
+			return fmt.Sprintf("label %s=%s", key, value)
+		}
+	}
+	for key, value := range spec.ProtectedAnnotations {
+		if v, ok := obj.GetAnnotations()[key]; ok && v == value {
+			return fmt.Sprintf("annotation %s=%s", key, value)
+		}
+	}
+	return ""
+}
+
+// matchesKind reports whether a protected kind entry matches the kind. A bare kind matches any group.
+func matchesKind(entry string, gvk schema.GroupVersionKind) bool {
+	if !strings.ContainsAny(entry, "./") {
+		return entry == gvk.Kind
+	}
+	parsed, err := ParseResourceType(entry)
+	if err != nil {
+		return false
+	}
+	return parsed.Kind == gvk.Kind && parsed.Group == gvk.Group
+}
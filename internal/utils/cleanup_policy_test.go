@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCleanupPolicyProtection(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = cronschedulesv1.AddToScheme(scheme)
+
+	expired := map[string]string{"cleanup": ""}
+	configMap := func(namespace, name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, Annotations: expired}}
+	}
+	clusterRole := func(name string, labels map[string]string) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: expired}}
+	}
+
+	objs := []client.Object{
+		&cronschedulesv1.CleanupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+			Spec: cronschedulesv1.CleanupPolicySpec{
+				ProtectedNamespaces:   []string{"payments"},
+				ProtectedKinds:        []string{"Secret"},
+				ProtectedNamePatterns: []string{"*-prod"},
+				ProtectedLabels:       map[string]string{"tier": "critical"},
+			},
+		},
+		configMap("default", "stale", nil),
+		configMap("default", "api-prod", nil),
+		configMap("default", "labeled", map[string]string{"tier": "critical"}),
+		configMap("default", "protected", map[string]string{ProtectedLabel: "true"}),
+		configMap("default", "marked", map[string]string{ProtectedKey: "true"}),
+		configMap("payments", "stale", nil),
+		configMap("kube-system", "stale", nil),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default", Annotations: expired}},
+		clusterRole("ci-runner", nil),
+		clusterRole("system:controller:foo", nil),
+		clusterRole("admin", map[string]string{bootstrappingLabel: "rbac-defaults"}),
+	}
+	k8sClient := &K8sClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+
+	result, err := runCleanup(ctx, k8sClient, &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup",
+		ResourceTypes: []string{"ConfigMap", "Secret", "ClusterRole"},
+		Namespaces:    []string{"default", "payments", "kube-system"},
+	}, "default")
+	if err != nil {
+		t.Fatalf("runCleanup returned error: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("expected 2 deleted resources, got %d (skipped %v)", result.Deleted, result.Skipped)
+	}
+
+	exists := func(obj client.Object, namespace, name string) bool {
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("failed to get %s/%s: %v", namespace, name, err)
+		}
+		return err == nil
+	}
+	if exists(&corev1.ConfigMap{}, "default", "stale") || exists(&rbacv1.ClusterRole{}, "", "ci-runner") {
+		t.Errorf("expected unprotected resources to be deleted")
+	}
+
+	wantReasons := map[string]string{
+		"default/api-prod":      "name pattern *-prod protected by CleanupPolicy platform",
+		"default/labeled":       "label tier=critical protected by CleanupPolicy platform",
+		"default/protected":     "labeled protected=true",
+		"default/marked":        "marked with " + ProtectedKey,
+		"default/stale":         "kind Secret protected by CleanupPolicy platform",
+		"payments":              "namespace payments protected by CleanupPolicy platform",
+		"kube-system":           "kube-system is always protected",
+		"system:controller:foo": "Kubernetes bootstrap RBAC",
+		"admin":                 "Kubernetes bootstrap RBAC",
+	}
+	got := map[string]string{}
+	for _, skipped := range result.Skipped {
+		key := skipped.Name
+		if skipped.Namespace != "" {
+			key = skipped.Namespace + "/" + skipped.Name
+		}
+		got[key] = skipped.Reason
+	}
+	for key, want := range wantReasons {
+		if !strings.Contains(got[key], want) {
+			t.Errorf("%s: skip reason %q, want %q", key, got[key], want)
+		}
+	}
+}
+
+func TestMatchesKind(t *testing.T) {
+	clusterRole := rbacv1.SchemeGroupVersion.WithKind("ClusterRole")
+	tests := []struct {
+		entry string
+		want  bool
+	}{
+		{entry: "ClusterRole", want: true},
+		{entry: "ClusterRole.rbac.authorization.k8s.io", want: true},
+		{entry: "rbac.authorization.k8s.io/v1/ClusterRole", want: true},
+		{entry: "ClusterRole.example.com", want: false},
+		{entry: "Role", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchesKind(tt.entry, clusterRole); got != tt.want {
+			t.Errorf("matchesKind(%q) = %v, want %v", tt.entry, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestDeleteResourceOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	var received client.DeleteOptions
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "gone", Namespace: "default"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default", Finalizers: []string{"example.com/keep"}}},
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				received = client.DeleteOptions{}
+				received.ApplyOptions(opts)
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		PropagationPolicy:  string(metav1.DeletePropagationForeground),
+		GracePeriodSeconds: ptr.To[int64](5),
+		WaitForDeletion:    true,
+		DeletionTimeout:    "1s",
+	}
+
+	gone := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "gone", Namespace: "default"}}
+	if deleted, err := k8sClient.deleteResource(ctx, gone, cleanupConfig); deleted != 1 || err != nil {
+		t.Errorf("expected resource to be counted as deleted, got %d, %v", deleted, err)
+	}
+	if received.PropagationPolicy == nil || *received.PropagationPolicy != metav1.DeletePropagationForeground {
+		t.Errorf("expected Foreground propagation, got %v", received.PropagationPolicy)
+	}
+	if received.GracePeriodSeconds == nil || *received.GracePeriodSeconds != 5 {
+		t.Errorf("expected grace period 5, got %v", received.GracePeriodSeconds)
+	}
+
+	stuck := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default"}}
+	if deleted, err := k8sClient.deleteResource(ctx, stuck, cleanupConfig); deleted != 0 || !errors.Is(err, errDeletionPending) {
+		t.Errorf("expected resource blocked by a finalizer to be pending, got %d, %v", deleted, err)
+	}
+
+	plan := &CleanupPlan{now: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), reasons: map[client.Object]string{}}
+	result := &CleanupResult{}
+	if err := k8sClient.executeDeletion(ctx, plan, stuck, "", cleanupConfig, result); err != nil {
+		t.Fatalf("executeDeletion returned error: %v", err)
+	}
+	if result.Deleted != 0 || len(result.PendingDeletions) != 1 || result.Records[0].Action != cronschedulesv1.CleanupActionDeleting {
+		t.Fatalf("expected a pending deletion, got %+v", result)
+	}
+	pending := result.PendingDeletions[0]
+	if pending.APIVersion != "v1" || pending.Kind != "ConfigMap" || !pending.Deadline.Equal(&metav1.Time{Time: plan.now.Add(time.Second)}) {
+		t.Errorf("unexpected pending deletion %+v", pending)
+	}
+	if exists, err := k8sClient.DeletionPending(ctx, PendingDeletionObject(pending)); !exists || err != nil {
+		t.Errorf("expected the stuck resource to still exist, got %v, %v", exists, err)
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CountRunningJobs returns the number of unfinished Jobs in the namespace matching the labels
+func (c *K8sClient) CountRunningJobs(ctx context.Context, namespace string, matchLabels map[string]string) (int, error) {
+	jobs := &batchv1.JobList{}
+	if err := c.List(ctx, jobs, client.InNamespace(namespace), client.MatchingLabels(matchLabels)); err != nil {
+		return 0, err
+	}
+
+	running := 0
+	for i := range jobs.Items {
+		if !isJobFinished(&jobs.Items[i]) {
+			running++
+		}
+	}
+	return running, nil
+}
+
+// ListBusyPods returns the names of the target's running pods that don't report themselves idle.
+// A pod is idle when it sets idleAnnotation to "true" (if given) and has idleCondition True (if given).
+// Pods are listed from the API server, so that checking one target doesn't cache every pod of the cluster.
+func (c *K8sClient) ListBusyPods(ctx context.Context, targetResource TargetObject, idleAnnotation, idleCondition string) ([]string, error) {
+	selector, err := c.targetPodSelector(ctx, targetResource)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.uncachedReader().List(ctx, pods, client.InNamespace(targetResource.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	var busy []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if idleAnnotation != "" && pod.Annotations[idleAnnotation] != "true" {
+			busy = append(busy, pod.Name)
+			continue
+		}
+		if idleCondition != "" && !hasPodCondition(pod, corev1.PodConditionType(idleCondition)) {
+			busy = append(busy, pod.Name)
+		}
+	}
+	return busy, nil
+}
+
+// ListRunningPods returns the names of the target's pods that are pending or running, including pods
+// still terminating, which keep using their volumes until they are gone. They are listed from the API
+// server rather than a cluster-wide pod cache.
+func (c *K8sClient) ListRunningPods(ctx context.Context, targetResource TargetObject) ([]string, error) {
+	selector, err := c.targetPodSelector(ctx, targetResource)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.uncachedReader().List(ctx, pods, client.InNamespace(targetResource.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	var running []string
+	for i := range pods.Items {
+		if phase := pods.Items[i].Status.Phase; phase == corev1.PodSucceeded || phase == corev1.PodFailed {
+			continue
+		}
+		running = append(running, pods.Items[i].Name)
+	}
+	return running, nil
+}
+
+// targetPodSelector returns the pod selector of the target resource
+func (c *K8sClient) targetPodSelector(ctx context.Context, targetResource TargetObject) (labels.Selector, error) {
+	key := client.ObjectKey{Name: targetResource.Name, Namespace: targetResource.Namespace}
+
+	var selector *metav1.LabelSelector
+	switch targetResource.Kind {
+	case DeploymentKind:
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, deployment); err != nil {
+			return nil, err
+		}
+		selector = deployment.Spec.Selector
+	case StatefulSetKind:
+		statefulset := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, key, statefulset); err != nil {
+			return nil, err
+		}
+		selector = statefulset.Spec.Selector
+	default:
+		return nil, fmt.Errorf("unsupported target resource kind: %s", targetResource.Kind)
+	}
+
+	if selector == nil {
+		return nil, fmt.Errorf("%s %s has no pod selector", targetResource.Kind, targetResource.Name)
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+func isJobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCountRunningJobs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = batchv1.AddToScheme(scheme)
+
+	job := func(name string, conditions ...batchv1.JobCondition) client.Object {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "batch"}},
+			Status:     batchv1.JobStatus{Conditions: conditions},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		job("running"),
+		job("complete", batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+		job("failed", batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+	).Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+
+	running, err := k8sClient.CountRunningJobs(context.Background(), "default", map[string]string{"app": "batch"})
+	if err != nil {
+		t.Fatalf("CountRunningJobs returned error: %v", err)
+	}
+	if running != 1 {
+		t.Errorf("expected 1 running job, got %d", running)
+	}
+}
+
+func TestListBusyPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "worker"}},
+		},
+	}
+	pod := func(name string, phase corev1.PodPhase, annotations map[string]string, idle corev1.ConditionStatus) client.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "worker"},
+				Annotations: annotations,
+			},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: "example.com/idle", Status: idle}},
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		deployment,
+		pod("idle", corev1.PodRunning, map[string]string{"example.com/idle": "true"}, corev1.ConditionTrue),
+		pod("busy-annotation", corev1.PodRunning, nil, corev1.ConditionTrue),
+		pod("busy-condition", corev1.PodRunning, map[string]string{"example.com/idle": "true"}, corev1.ConditionFalse),
+		pod("pending", corev1.PodPending, nil, corev1.ConditionFalse),
+	).Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+	target := TargetObject{TargetRef: cronschedulesv1.TargetRef{Name: "worker", Namespace: "default", Kind: DeploymentKind}}
+
+	tests := []struct {
+		name       string
+		annotation string
+		condition  string
+		want       []string
+	}{
+		{name: "annotation only", annotation: "example.com/idle", want: []string{"busy-annotation"}},
+		{name: "condition only", condition: "example.com/idle", want: []string{"busy-condition"}},
+		{name: "both", annotation: "example.com/idle", condition: "example.com/idle", want: []string{"busy-annotation", "busy-condition"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			busy, err := k8sClient.ListBusyPods(context.Background(), target, tt.annotation, tt.condition)
+			if err != nil {
+				t.Fatalf("ListBusyPods returned error: %v", err)
+			}
+			if !reflect.DeepEqual(busy, tt.want) {
+				t.Errorf("expected busy pods %v, got %v", tt.want, busy)
+			}
+		})
+	}
+}
+
+func TestListRunningPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	statefulset := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+	}
+	pod := func(name string, phase corev1.PodPhase, labels map[string]string) client.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	dbLabels := map[string]string{"app": "db"}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		statefulset,
+		pod("db-0", corev1.PodRunning, dbLabels),
+		pod("db-1", corev1.PodPending, dbLabels),
+		pod("db-backup", corev1.PodSucceeded, dbLabels),
+		pod("db-migrate", corev1.PodFailed, dbLabels),
+		pod("web-0", corev1.PodRunning, map[string]string{"app": "web"}),
+	).Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+	target := TargetObject{TargetRef: cronschedulesv1.TargetRef{Name: "db", Namespace: "default", Kind: StatefulSetKind}}
+
+	running, err := k8sClient.ListRunningPods(context.Background(), target)
+	if err != nil {
+		t.Fatalf("ListRunningPods returned error: %v", err)
+	}
+	if want := []string{"db-0", "db-1"}; !reflect.DeepEqual(running, want) {
+		t.Errorf("expected running pods %v, got %v", want, running)
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Day is the duration of the "d" unit
+	Day = 24 * time.Hour
+	// Week is the duration of the "w" unit
+	Week = 7 * Day
+)
+
+// ParseDuration parses a duration string like time.ParseDuration, with the additional units
+// "d" (24h) and "w" (7d). Units can be combined, e.g. "7d", "1w2d12h" or "1.5d".
+func ParseDuration(s string) (time.Duration, error) {
+	value := s
+	negative := false
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+	if value == "0" {
+		return 0, nil
+	}
+	if value == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var total time.Duration
+	for value != "" {
+		// Number, possibly fractional
+		i := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		number := value[:i]
+		value = value[i:]
+
+		// Unit, up to the next digit
+		j := strings.IndexFunc(value, func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if j < 0 {
+			j = len(value)
+		}
+		unit := value[:j]
+		value = value[j:]
+
+		var part time.Duration
+		switch unit {
+		case "d", "w":
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			scale := Day
+			if unit == "w" {
+				scale = Week
+			}
+			part = time.Duration(n * float64(scale))
+		default:
+			d, err := time.ParseDuration(number + unit)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q: unknown unit %q", s, unit)
+			}
+			part = d
+		}
+		total += part
+	}
+
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// FormatDuration formats a duration with the "w" and "d" units where they apply, e.g. "1w2d12h0m0s".
+// The result can be parsed by ParseDuration.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+
+	var b strings.Builder
+	if weeks := d / Week; weeks > 0 {
+		fmt.Fprintf(&b, "%dw", weeks)
+		d -= weeks * Week
+	}
+	if days := d / Day; days > 0 {
+		fmt.Fprintf(&b, "%dd", days)
+		d -= days * Day
+	}
+	if d > 0 || b.Len() == 0 {
+		b.WriteString(d.String())
+	}
+	return b.String()
+}
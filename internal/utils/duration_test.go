@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "24h", want: 24 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "7d", want: 7 * Day},
+		{input: "2w", want: 2 * Week},
+		{input: "1w2d12h", want: Week + 2*Day + 12*time.Hour},
+		{input: "1.5d", want: 36 * time.Hour},
+		{input: "1d30m15s", want: Day + 30*time.Minute + 15*time.Second},
+		{input: "-1d", want: -Day},
+		{input: "0", want: 0},
+		{input: "", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "7", wantErr: true},
+		{input: "7y", wantErr: true},
+		{input: "7 days", wantErr: true},
+		{input: "2025-01-20", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseDuration(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{input: 0, want: "0s"},
+		{input: 90 * time.Minute, want: "1h30m0s"},
+		{input: 7 * Day, want: "1w"},
+		{input: Week + 2*Day + 12*time.Hour, want: "1w2d12h0m0s"},
+		{input: -Day, want: "-1d"},
+	}
+
+	for _, tt := range tests {
+		got := FormatDuration(tt.input)
+		if got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.input, got, tt.want)
+		}
+		if parsed, err := ParseDuration(got); err != nil || parsed != tt.input {
+			t.Errorf("ParseDuration(FormatDuration(%v)) = %v, %v", tt.input, parsed, err)
+		}
+	}
+}
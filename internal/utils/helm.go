@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// HelmReleaseResourceType is the cleanup resource type of Helm releases. A release is cleaned up as a
+// whole: the resources of its manifest and all its history Secrets.
+const HelmReleaseResourceType = "HelmRelease"
+
+// gzipMagic starts gzip-compressed release data
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// manifestSeparator splits a Helm release manifest into its documents
+var manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// helmRelease holds the fields of a Helm release record that cleanup needs
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Manifest  string `json:"manifest"`
+	Info      struct {
+		FirstDeployed time.Time `json:"first_deployed"`
+		LastDeployed  time.Time `json:"last_deployed"`
+		Status        string    `json:"status"`
+	} `json:"info"`
+}
+
+// decodeHelmRelease decodes the release record Helm stores in a release Secret: base64-encoded JSON,
+// usually gzip-compressed
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+	}
+
+	release := &helmRelease{}
+	if err := json.Unmarshal(decoded, release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	return release, nil
+}
+
+// manifestObjects parses the resources of a release manifest
+func manifestObjects(manifest string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, document := range manifestSeparator.Split(manifest, -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		content := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &content); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		// Documents holding only comments, e.g. of templates rendering nothing
+		if len(content) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// planHelmReleases plans the cleanup of the Helm releases of a namespace. Each release is deleted as one
+// group: the live resources of its latest manifest, its older history Secrets and, last, its latest
+// release Secret. Anyone able to create a Secret can forge a release, so the release is pinned to the
+// namespace of its Secret and only the resources of its manifest in the cleanup namespaces are included,
+// never cluster-scoped ones. The cleanup annotation is read from the latest release Secret only; durations and the orphan age count from the first deployment of the release, or
+// from its last upgrade with an age source other than creation.
+func (c *K8sClient) planHelmReleases(ctx context.Context, namespace string, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) error {
+	logger := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(namespace), client.MatchingLabels{"owner": "helm"}); err != nil {
+		return fmt.Errorf("failed to list Helm release secrets in namespace %s: %w", namespace, err)
+	}
+
+	// History Secrets of each release, latest version first
+	history := map[string][]*corev1.Secret{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != helmReleaseSecretType {
+			continue
+		}
+		history[secret.Labels["name"]] = append(history[secret.Labels["name"]], secret)
+	}
+	names := make([]string, 0, len(history))
+	for name, releaseSecrets := range history {
+		sort.Slice(releaseSecrets, func(i, j int) bool {
+			return releaseVersion(releaseSecrets[i]) > releaseVersion(releaseSecrets[j])
+		})
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !plan.filter.matchesName(name) {
+			continue
+		}
+		plan.Matched++
+		releaseSecrets := history[name]
+		latest := releaseSecrets[0]
+
+		release, err := decodeHelmRelease(latest.Data["release"])
+		if err != nil {
+			logger.Error(err, "Failed to read Helm release, keeping it", "release", name, "namespace", namespace)
+			plan.skip(HelmReleaseResourceType, latest, "release could not be decoded")
+			continue
+		}
+		release.Namespace = namespace
+		objects, err := c.liveReleaseObjects(ctx, release, plan.namespaces)
+		if err != nil {
+			logger.Error(err, "Failed to find Helm release resources, keeping it", "release", name, "namespace", namespace)
+			plan.skip(HelmReleaseResourceType, latest, "release resources could not be read")
+			continue
+		}
+
+		reason := c.helmReleaseCleanupReason(ctx, latest, release, cleanupConfig)
+		if reason == "" {
+			continue
+		}
+
+		related := objects
+		for _, secret := range releaseSecrets[1:] {
+			related = append(related, secret)
+		}
+		if protected := plan.protectedMember(related); protected != "" {
+			plan.skip(HelmReleaseResourceType, latest, protected)
+			continue
+		}
+		logger.Info("Helm release due for cleanup", "release", name, "namespace", namespace, "reason", reason, "resources", len(objects))
+		plan.addGroup(latest, reason, &cleanupGroup{
+			name:         HelmReleaseResourceType + "/" + name,
+			failureEvent: "HelmReleaseCleanupFailed",
+			related:      related,
+		})
+	}
+	return nil
+}
+
+// releaseVersion returns the revision of a Helm release Secret
+func releaseVersion(secret *corev1.Secret) int {
+	version, _ := strconv.Atoi(secret.Labels["version"])
+	return version
+}
+
+// liveReleaseObjects returns the resources of the release manifest that still exist in the namespaces.
+// Cluster-scoped resources and resources in other namespaces are left out.
+func (c *K8sClient) liveReleaseObjects(ctx context.Context, release *helmRelease, namespaces []string) ([]client.Object, error) {
+	logger := log.FromContext(ctx)
+
+	objects, err := manifestObjects(release.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var live []client.Object
+	for _, obj := range objects {
+		mapping, err := c.RESTMapper().RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				// The kind is no longer served, so the resource is gone
+				continue
+			}
+			return nil, err
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			logger.Info("Leaving cluster-scoped Helm release resource alone", "release", release.Name,
+				"kind", obj.GetKind(), "name", obj.GetName())
+			continue
+		}
+		key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if key.Namespace == "" {
+			key.Namespace = release.Namespace
+		}
+		if !slices.Contains(namespaces, key.Namespace) {
+			logger.Info("Leaving Helm release resource outside the cleanup namespaces alone", "release", release.Name,
+				"kind", obj.GetKind(), "name", obj.GetName(), "namespace", key.Namespace)
+			continue
+		}
+
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := c.Get(ctx, key, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		live = append(live, current)
+	}
+	return live, nil
+}
+
+// helmReleaseCleanupReason returns why a Helm release is due for cleanup, or an empty string if it is kept.
+// The cleanup annotation is only read from the latest release Secret: whoever can annotate a single resource
+// of the release must not be able to get the whole release deleted.
+func (c *K8sClient) helmReleaseCleanupReason(ctx context.Context, latest *corev1.Secret, release *helmRelease, cleanupConfig *cronschedulesv1.CleanupConfig) string {
+	logger := log.FromContext(ctx)
+
+	value, ok := latest.Annotations[cleanupConfig.AnnotationKey]
+
+	deployed := release.Info.FirstDeployed
+	if deployed.IsZero() {
+		deployed = latest.CreationTimestamp.Time
+	}
+	// Age sources other than creation count from the last upgrade of the release
+	if source := cleanupConfig.AgeSource; source != "" && source != cronschedulesv1.AgeSourceCreation && release.Info.LastDeployed.After(deployed) {
+		deployed = release.Info.LastDeployed
+	}
+
+	if ok {
+		if value == "" {
+			return cronschedulesv1.CleanupReasonImmediate
+		}
+		deadline, err := cleanupDeadline(value, deployed, c.cleanupDates(cleanupConfig))
+		if err != nil {
+			logger.Error(nil, "Invalid cleanup time format", "release", release.Name, "value", value, "supportedFormats", cleanupTimeFormats)
+			c.recordEvent(latest, corev1.EventTypeWarning, "InvalidCleanupAnnotation",
+				fmt.Sprintf("cleanup annotation value %q is not a valid %s; Helm release %s will not be cleaned up", value, cleanupTimeFormats, release.Name))
+			return ""
+		}
+		if time.Now().After(deadline) {
+			return cronschedulesv1.CleanupReasonAnnotation
+		}
+		return ""
+	}
+
+	if cleanupConfig.CleanupOrphanResources {
+		maxAge, err := ParseDuration(cleanupConfig.OrphanResourceMaxAge)
+		if err == nil && time.Since(deployed) > maxAge {
+			return cronschedulesv1.CleanupReasonOrphanAge
+		}
+	}
+	return ""
+}
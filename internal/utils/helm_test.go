@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const previewManifest = `---
+# Source: preview/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: preview
+---
+# Source: preview/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: preview-config
+---
+# Source: preview/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: preview
+---
+# Source: preview/templates/removed.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: already-gone
+`
+
+// helmReleaseSecret builds a release Secret the way Helm stores it: gzip-compressed, base64-encoded JSON
+func helmReleaseSecret(t *testing.T, name string, version int, deployed time.Time, annotations map[string]string) *corev1.Secret {
+	t.Helper()
+	return helmReleaseRecordSecret(t, name, version, annotations, map[string]interface{}{
+		"name":      name,
+		"namespace": "default",
+		"version":   version,
+		"manifest":  previewManifest,
+		"info":      map[string]interface{}{"first_deployed": deployed, "last_deployed": deployed, "status": "deployed"},
+	})
+}
+
+// helmReleaseRecordSecret stores a release record in a release Secret of the default namespace
+func helmReleaseRecordSecret(t *testing.T, name string, version int, annotations map[string]string, record map[string]interface{}) *corev1.Secret {
+	t.Helper()
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sh.helm.release.v1." + name + ".v" + strconv.Itoa(version),
+			Namespace:   "default",
+			Labels:      map[string]string{"owner": "helm", "name": name, "version": strconv.Itoa(version), "status": "deployed"},
+			Annotations: annotations,
+		},
+		Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(compressed.Bytes()))},
+	}
+}
+
+func TestDecodeHelmRelease(t *testing.T) {
+	deployed := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	secret := helmReleaseSecret(t, "preview", 3, deployed, nil)
+
+	release, err := decodeHelmRelease(secret.Data["release"])
+	if err != nil {
+		t.Fatalf("decodeHelmRelease() error = %v", err)
+	}
+	if release.Name != "preview" || release.Version != 3 || !release.Info.FirstDeployed.Equal(deployed) {
+		t.Errorf("decodeHelmRelease() = %+v", release)
+	}
+
+	objects, err := manifestObjects(release.Manifest)
+	if err != nil {
+		t.Fatalf("manifestObjects() error = %v", err)
+	}
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	if want := "Service/preview,ConfigMap/preview-config,Deployment/preview,ConfigMap/already-gone"; strings.Join(names, ",") != want {
+		t.Errorf("manifestObjects() = %v, want %s", names, want)
+	}
+
+	if _, err := decodeHelmRelease([]byte("not base64!")); err == nil {
+		t.Error("decodeHelmRelease() should fail on invalid data")
+	}
+}
+
+func TestHelmReleaseCleanup(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := time.Now().Add(-48 * time.Hour)
+
+	objects := func(annotations map[string]string) []client.Object {
+		return []client.Object{
+			helmReleaseSecret(t, "preview", 1, old, nil),
+			helmReleaseSecret(t, "preview", 2, old, annotations),
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "preview", Namespace: "default"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "preview-config", Namespace: "default"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "preview", Namespace: "default"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+		}
+	}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{HelmReleaseResourceType},
+	}
+
+	t.Run("deletes the release", func(t *testing.T) {
+		k8sClient := newOrphanTestClient(objects(map[string]string{"cleanup-after": "24h"})...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+
+		var deleted []string
+		for _, record := range result.Records {
+			if record.Group != "HelmRelease/preview" {
+				t.Errorf("record %s/%s has group %q", record.Kind, record.Name, record.Group)
+			}
+			if record.Action == cronschedulesv1.CleanupActionDeleted {
+				deleted = append(deleted, record.Kind+"/"+record.Name)
+			}
+		}
+		sort.Strings(deleted)
+		want := "ConfigMap/preview-config,Deployment/preview,Secret/sh.helm.release.v1.preview.v1,Secret/sh.helm.release.v1.preview.v2,Service/preview"
+		if strings.Join(deleted, ",") != want {
+			t.Errorf("deleted %v, want %s", deleted, want)
+		}
+		if last := result.Records[len(result.Records)-1]; last.Name != "sh.helm.release.v1.preview.v2" {
+			t.Errorf("last deleted %s, want the latest release Secret", last.Name)
+		}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "unrelated"}, &corev1.ConfigMap{}); err != nil {
+			t.Errorf("object outside the release should be kept, got %v", err)
+		}
+	})
+
+	t.Run("keeps a release before its deadline", func(t *testing.T) {
+		k8sClient := newOrphanTestClient(objects(map[string]string{"cleanup-after": "72h"})...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 0 {
+			t.Errorf("deleted = %d, want the release kept", result.Deleted)
+		}
+	})
+
+	t.Run("ignores the annotation on release resources", func(t *testing.T) {
+		objs := objects(nil)
+		objs[2].SetAnnotations(map[string]string{"cleanup-after": ""})
+		k8sClient := newOrphanTestClient(objs...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 0 {
+			t.Errorf("deleted = %d, want the release kept", result.Deleted)
+		}
+	})
+
+	t.Run("uses the orphan age of the release", func(t *testing.T) {
+		k8sClient := newOrphanTestClient(objects(nil)...)
+		orphanConfig := cleanupConfig.DeepCopy()
+		orphanConfig.CleanupOrphanResources = true
+		orphanConfig.OrphanResourceMaxAge = "24h"
+		result, err := runCleanup(ctx, k8sClient, orphanConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 5 {
+			t.Errorf("deleted = %d, want the 5 objects of the release", result.Deleted)
+		}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "preview"}, &appsv1.Deployment{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("release Deployment should be deleted, got %v", err)
+		}
+	})
+
+	// forgedRelease is a release Secret anyone able to create Secrets in default could have written,
+	// claiming a release in payments
+	forgedRelease := func(manifest string) *corev1.Secret {
+		return helmReleaseRecordSecret(t, "forged", 1, map[string]string{"cleanup-after": ""}, map[string]interface{}{
+			"name":      "forged",
+			"namespace": "payments",
+			"version":   1,
+			"manifest":  manifest,
+		})
+	}
+	expectOnlyReleaseDeleted := func(t *testing.T, manifest string, kept ...client.Object) {
+		forged := forgedRelease(manifest)
+		k8sClient := newOrphanTestClient(append([]client.Object{forged}, kept...)...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 1 || result.Records[0].Name != forged.Name {
+			t.Errorf("result = %+v, want only the forged release Secret deleted", result)
+		}
+		for _, obj := range kept {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				t.Errorf("%T %s should be kept, got %v", obj, obj.GetName(), err)
+			}
+		}
+	}
+
+	t.Run("keeps the resources of a forged release in other namespaces", func(t *testing.T) {
+		// ledger is listed without a namespace, which the release claims to be payments
+		expectOnlyReleaseDeleted(t, `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ledger
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: db-config
+  namespace: payments
+`,
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ledger", Namespace: "payments"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "db-config", Namespace: "payments"}},
+		)
+	})
+
+	t.Run("keeps the cluster-scoped resources of a forged release", func(t *testing.T) {
+		expectOnlyReleaseDeleted(t, `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: payments
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: admin
+`,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		)
+	})
+
+	t.Run("keeps a release with a protected resource", func(t *testing.T) {
+		objs := objects(map[string]string{"cleanup-after": ""})
+		objs[3].SetLabels(map[string]string{ProtectedKey: "true"})
+		k8sClient := newOrphanTestClient(objs...)
+		result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+		if err != nil {
+			t.Fatalf("runCleanup() error = %v", err)
+		}
+		if result.Deleted != 0 || len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0].Reason, "preview-config") {
+			t.Errorf("result = %+v, want the release kept", result)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const (
+	// LabelKeyHookOwner is set on hook Jobs to the name of the CronJobScaleDown that created them
+	LabelKeyHookOwner = "cronjob-scale-down-operator/owner"
+	// LabelKeyHookPhase is set on hook Jobs to the hook phase they run for
+	LabelKeyHookPhase = "cronjob-scale-down-operator/hook-phase"
+
+	// maxJobNameLength keeps the job-name label of the hook's pods within the label value limit
+	maxJobNameLength = 63
+)
+
+// HookJobResult describes the state of a hook Job
+type HookJobResult struct {
+	Result         string
+	Message        string
+	CompletionTime *time.Time
+}
+
+// CreateHookJob creates the Job for a scale hook and returns its name. The Job always runs in the
+// CronJobScaleDown's namespace and is owned by it, so that writing a CronJobScaleDown doesn't let anyone run
+// pods in other namespaces, and hook Jobs are garbage-collected with it.
+func (c *K8sClient) CreateHookJob(ctx context.Context, owner *cronschedulesv1.CronJobScaleDown, phase string, hook *cronschedulesv1.ScaleHook, timeout time.Duration, now time.Time) (string, error) {
+	logger := log.FromContext(ctx)
+
+	if hook.JobTemplate == nil {
+		return "", fmt.Errorf("hook %s has no job template", phase)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: *hook.JobTemplate.ObjectMeta.DeepCopy(),
+		Spec:       *hook.JobTemplate.Spec.DeepCopy(),
+	}
+	job.Name = hookJobName(owner.Name, phase, now)
+	job.GenerateName = ""
+	job.Namespace = owner.Namespace
+
+	labels := job.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelKeyHookOwner] = owner.Name
+	labels[LabelKeyHookPhase] = phase
+	job.SetLabels(labels)
+
+	// Let the Job controller stop the hook at the same time the operator gives up on it
+	if job.Spec.ActiveDeadlineSeconds == nil && timeout > 0 {
+		job.Spec.ActiveDeadlineSeconds = ptr.To(int64(timeout.Seconds()))
+	}
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	if err := controllerutil.SetControllerReference(owner, job, c.Scheme()); err != nil {
+		return "", fmt.Errorf("failed to set owner reference on hook job: %w", err)
+	}
+
+	if err := c.Create(ctx, job); err != nil {
+		logger.Error(err, "Failed to create hook job", "phase", phase, "name", job.Name, "namespace", job.Namespace)
+		return "", err
+	}
+
+	logger.Info("Created hook job", "phase", phase, "name", job.Name, "namespace", job.Namespace)
+	return job.Name, nil
+}
+
+// GetHookJobResult returns the result of a hook Job from its conditions
+func (c *K8sClient) GetHookJobResult(ctx context.Context, namespace, name string) (HookJobResult, error) {
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return HookJobResult{Result: cronschedulesv1.HookResultFailed, Message: "hook job no longer exists"}, nil
+		}
+		return HookJobResult{}, err
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return HookJobResult{
+				Result:         cronschedulesv1.HookResultSucceeded,
+				Message:        "hook job completed",
+				CompletionTime: jobCompletionTime(job, condition),
+			}, nil
+		case batchv1.JobFailed:
+			return HookJobResult{
+				Result:         cronschedulesv1.HookResultFailed,
+				Message:        fmt.Sprintf("hook job failed: %s %s", condition.Reason, condition.Message),
+				CompletionTime: jobCompletionTime(job, condition),
+			}, nil
+		}
+	}
+
+	return HookJobResult{Result: cronschedulesv1.HookResultRunning}, nil
+}
+
+// DeleteHookJob deletes a hook Job and its pods
+func (c *K8sClient) DeleteHookJob(ctx context.Context, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return client.IgnoreNotFound(c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
+
+func jobCompletionTime(job *batchv1.Job, condition batchv1.JobCondition) *time.Time {
+	if job.Status.CompletionTime != nil {
+		return &job.Status.CompletionTime.Time
+	}
+	return &condition.LastTransitionTime.Time
+}
+
+// hookJobName builds a unique, DNS-compatible name for a hook Job
+func hookJobName(ownerName, phase string, now time.Time) string {
+	suffix := fmt.Sprintf("-%s-%d", strings.ToLower(phase), now.Unix())
+	if len(ownerName)+len(suffix) > maxJobNameLength {
+		ownerName = strings.TrimRight(ownerName[:maxJobNameLength-len(suffix)], "-.")
+	}
+	return ownerName + suffix
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestHookJobLifecycle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = batchv1.AddToScheme(scheme)
+	_ = cronschedulesv1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&batchv1.Job{}).Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	owner := &cronschedulesv1.CronJobScaleDown{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: "uid-1"},
+	}
+	hook := &cronschedulesv1.ScaleHook{
+		JobTemplate: &batchv1.JobTemplateSpec{
+			// The template can't move the Job out of the CronJobScaleDown's namespace
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system"},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "drain", Image: "busybox"}},
+					},
+				},
+			},
+		},
+	}
+	now := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+
+	name, err := k8sClient.CreateHookJob(ctx, owner, cronschedulesv1.HookPhasePreScaleDown, hook, 5*time.Minute, now)
+	if err != nil {
+		t.Fatalf("CreateHookJob returned error: %v", err)
+	}
+
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Name: name, Namespace: "default"}, job); err != nil {
+		t.Fatalf("hook job was not created: %v", err)
+	}
+	if job.Labels[LabelKeyHookPhase] != cronschedulesv1.HookPhasePreScaleDown {
+		t.Errorf("expected phase label %q, got %q", cronschedulesv1.HookPhasePreScaleDown, job.Labels[LabelKeyHookPhase])
+	}
+	if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds != 300 {
+		t.Errorf("expected activeDeadlineSeconds 300, got %v", job.Spec.ActiveDeadlineSeconds)
+	}
+	if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("expected restartPolicy Never, got %q", job.Spec.Template.Spec.RestartPolicy)
+	}
+	if len(job.OwnerReferences) != 1 {
+		t.Errorf("expected an owner reference, got %d", len(job.OwnerReferences))
+	}
+
+	result, err := k8sClient.GetHookJobResult(ctx, "default", name)
+	if err != nil || result.Result != cronschedulesv1.HookResultRunning {
+		t.Fatalf("expected running hook, got %q (err %v)", result.Result, err)
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if err := fakeClient.Status().Update(ctx, job); err != nil {
+		t.Fatalf("failed to update job status: %v", err)
+	}
+	result, err = k8sClient.GetHookJobResult(ctx, "default", name)
+	if err != nil || result.Result != cronschedulesv1.HookResultSucceeded {
+		t.Fatalf("expected succeeded hook, got %q (err %v)", result.Result, err)
+	}
+
+	if err := k8sClient.DeleteHookJob(ctx, "default", name); err != nil {
+		t.Fatalf("DeleteHookJob returned error: %v", err)
+	}
+	result, err = k8sClient.GetHookJobResult(ctx, "default", name)
+	if err != nil || result.Result != cronschedulesv1.HookResultFailed {
+		t.Errorf("expected deleted hook job to count as failed, got %q (err %v)", result.Result, err)
+	}
+}
+
+func TestHookJobName(t *testing.T) {
+	now := time.Unix(1736200800, 0)
+
+	name := hookJobName("nightly", cronschedulesv1.HookPhasePostScaleUp, now)
+	if name != "nightly-postscaleup-1736200800" {
+		t.Errorf("unexpected hook job name %q", name)
+	}
+
+	long := hookJobName(strings.Repeat("a", 70), cronschedulesv1.HookPhasePreScaleDown, now)
+	if len(long) > maxJobNameLength {
+		t.Errorf("hook job name %q exceeds %d characters", long, maxJobNameLength)
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const (
+	// HeaderHookSignature carries the HMAC-SHA256 signature of an HTTP hook payload, as "sha256=<hex>"
+	HeaderHookSignature = "X-CronJobScaleDown-Signature"
+	// HeaderHookPhase carries the phase of the hook being called
+	HeaderHookPhase = "X-CronJobScaleDown-Phase"
+
+	// maxHookResponseBody bounds how much of a failed response is kept in the hook status message
+	maxHookResponseBody = 256
+)
+
+// HookEvent is the JSON payload POSTed by HTTP hooks
+type HookEvent struct {
+	CronJobScaleDown HookEventObject `json:"cronJobScaleDown"`
+	Target           HookEventObject `json:"target"`
+	// Direction is "down" or "up"
+	Direction string `json:"direction"`
+	Phase     string `json:"phase"`
+	// Replicas is the target's replica count when the hook runs
+	Replicas      *int32    `json:"replicas,omitempty"`
+	ScheduledTime time.Time `json:"scheduledTime"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// HookEventObject identifies an object in a HookEvent
+type HookEventObject struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+}
+
+// GetSecretKey returns the value of a key of a Secret. The Secret is read from the API server, so that
+// reading one key doesn't cache every Secret of the cluster.
+func (c *K8sClient) GetSecretKey(ctx context.Context, namespace string, ref *cronschedulesv1.SecretKeyRef) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.uncachedReader().Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return value, nil
+}
+
+// SignHookPayload returns the HMAC-SHA256 signature of body, formatted for the HeaderHookSignature header
+func SignHookPayload(body, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostHookEvent POSTs the event as JSON to url once, signing it when signingKey is set. It reports
+// whether a failure may be retried: connection errors and 5xx or 429 responses are, any other non-2xx
+// response isn't. Retries are left to the caller so that a slow endpoint doesn't hold up reconciles.
+func PostHookEvent(ctx context.Context, url string, event HookEvent, signingKey []byte, requestTimeout time.Duration) (bool, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode hook event: %w", err)
+	}
+	return postHookEventOnce(ctx, &http.Client{Timeout: requestTimeout}, url, event.Phase, body, signingKey)
+}
+
+func postHookEventOnce(ctx context.Context, httpClient *http.Client, url, phase string, body, signingKey []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderHookPhase, phase)
+	if len(signingKey) > 0 {
+		req.Header.Set(HeaderHookSignature, SignHookPayload(body, signingKey))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("hook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookResponseBody))
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("hook endpoint returned %s: %s", resp.Status, bytes.TrimSpace(snippet))
+}
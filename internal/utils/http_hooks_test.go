@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestPostHookEvent(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	key := []byte("s3cr3t")
+	event := HookEvent{
+		CronJobScaleDown: HookEventObject{Name: "nightly", Namespace: "default"},
+		Target:           HookEventObject{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Namespace: "default"},
+		Direction:        "down",
+		Phase:            cronschedulesv1.HookPhasePreScaleDown,
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantRetryable bool
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "server error is retryable", status: http.StatusBadGateway, wantErr: true, wantRetryable: true},
+		{name: "throttling is retryable", status: http.StatusTooManyRequests, wantErr: true, wantRetryable: true},
+		{name: "client error vetoes without retry", status: http.StatusConflict, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+
+				body, _ := io.ReadAll(r.Body)
+				if got := r.Header.Get(HeaderHookSignature); got != SignHookPayload(body, key) {
+					t.Errorf("unexpected signature %q", got)
+				}
+				var received HookEvent
+				if err := json.Unmarshal(body, &received); err != nil || received.Target.Name != "api" {
+					t.Errorf("unexpected payload %s (err %v)", body, err)
+				}
+
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			retryable, err := PostHookEvent(ctx, server.URL, event, key, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostHookEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if retryable != tt.wantRetryable {
+				t.Errorf("expected retryable %v, got %v", tt.wantRetryable, retryable)
+			}
+			if attempts != 1 {
+				t.Errorf("expected a single attempt, got %d", attempts)
+			}
+		})
+	}
+}
@@ -2,31 +2,94 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
 )
 
+// FieldManager is the field manager the operator's changes are recorded under, which the lastUpdate age
+// source ignores
+const FieldManager = "cronjob-scale-down-operator"
+
 // K8sClient wraps a kubernetes client
 type K8sClient struct {
 	client.Client
+
+	// Recorder emits Events on cleaned up resources, e.g. for unparseable cleanup annotations. Optional.
+	Recorder record.EventRecorder
+
+	// APIReader reads directly from the API server for lists with a field selector, which the cache
+	// doesn't support, and for objects that shouldn't be cached. Optional; the client is used when nil.
+	APIReader client.Reader
+
+	// ArchiveSettings confine where cleanup archives are written
+	ArchiveSettings ArchiveSettings
+
+	// Location is the time zone of the CronJobScaleDown, in which cleanup annotation dates and times without
+	// an offset are interpreted. UTC when nil.
+	Location *time.Location
+
+	// DeleteLimiter throttles the deletions of cleanup runs with deletesPerSecond. It is kept across
+	// reconciles so that the rate holds across the parts of a run; PlanCleanup creates one when nil.
+	DeleteLimiter *rate.Limiter
+
+	// references caches the ConfigMaps and Secrets referenced per namespace for orphan detection
+	references map[string]*namespaceReferences
+}
+
+// uncachedReader returns the reader of objects that shouldn't be cached, such as single Secrets, whose
+// cache would hold every Secret of the cluster. It reads from the API server when an APIReader is configured.
+func (c *K8sClient) uncachedReader() client.Reader {
+	if c.APIReader != nil {
+		return c.APIReader
+	}
+	return c.Client
+}
+
+// recordEvent emits an Event on obj when a recorder is configured
+func (c *K8sClient) recordEvent(obj client.Object, eventType, reason, message string) {
+	if c.Recorder == nil {
+		return
+	}
+	c.Recorder.Event(obj, eventType, reason, message)
 }
 
 type TargetObject struct {
 	cronschedulesv1.TargetRef
 }
 
+// cleanupTimeFormats describes the accepted values of the cleanup annotation
+const cleanupTimeFormats = "duration (24h, 7d, 1w2d12h), RFC3339 (2006-01-02T15:04:05Z07:00, offset optional), or date (2006-01-02)"
+
+// noOffsetTimeLayout is the RFC3339 layout without an offset, interpreted in the time zone of the CronJobScaleDown
+const noOffsetTimeLayout = "2006-01-02T15:04:05"
+
+// DisplayTimeLayout formats deletion times in the time zone of the CronJobScaleDown for events and the web UI
+const DisplayTimeLayout = "2006-01-02 15:04:05 MST"
+
+// defaultDeletionTimeout bounds waiting for a deleted resource when deletionTimeout isn't set
+const defaultDeletionTimeout = 2 * time.Minute
+
+// errDeletionPending reports a resource deleted with waitForDeletion that still exists
+var errDeletionPending = errors.New("deletion pending")
+
 const (
 	annotationKeyOriginalReplicas = "cronjob-scale-down-operator/original-replicas"
 	DeploymentKind                = "Deployment"
@@ -164,6 +227,34 @@ func (c *K8sClient) GetReplicasCount(ctx context.Context, targetResource TargetO
 	return replicas
 }
 
+// IsTargetReady reports whether the target resource has rolled out and all its desired replicas are ready
+func (c *K8sClient) IsTargetReady(ctx context.Context, targetResource TargetObject) (bool, error) {
+	key := client.ObjectKey{Name: targetResource.Name, Namespace: targetResource.Namespace}
+
+	switch targetResource.Kind {
+	case DeploymentKind:
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, deployment); err != nil {
+			return false, err
+		}
+		desired := ptr.Deref(deployment.Spec.Replicas, 1)
+		return deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.UpdatedReplicas >= desired &&
+			deployment.Status.AvailableReplicas >= desired, nil
+
+	case StatefulSetKind:
+		statefulset := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, key, statefulset); err != nil {
+			return false, err
+		}
+		desired := ptr.Deref(statefulset.Spec.Replicas, 1)
+		return statefulset.Status.ObservedGeneration >= statefulset.Generation &&
+			statefulset.Status.ReadyReplicas >= desired, nil
+	default:
+		return false, fmt.Errorf("unsupported target resource kind: %s", targetResource.Kind)
+	}
+}
+
 func (c *K8sClient) UpdateTargetResourceOriginalReplicasAnnotation(ctx context.Context, targetResource TargetObject) error {
 	logger := log.FromContext(ctx)
 	var targetResourceObject client.Object
@@ -270,198 +361,435 @@ func (c *K8sClient) ScaleUpTargetResource(ctx context.Context, targetRef TargetO
 	return nil
 }
 
-// CleanupResources finds and deletes resources based on cleanup configuration
-func (c *K8sClient) CleanupResources(ctx context.Context, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string) (int32, error) {
+// PlanCleanup finds the resources due for cleanup without deleting them. With paging, resume continues the
+// run paused by an earlier reconcile; it is nil for a new run.
+func (c *K8sClient) PlanCleanup(ctx context.Context, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string, resume *cronschedulesv1.CleanupContinuation) (*CleanupPlan, error) {
 	logger := log.FromContext(ctx)
 
 	if cleanupConfig == nil {
-		return 0, fmt.Errorf("cleanup config is nil")
+		return nil, fmt.Errorf("cleanup config is nil")
+	}
+
+	// CleanupPolicies are enforced ahead of the CronJobScaleDown's own configuration
+	protection, err := c.loadProtection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ParseResourceFilter(cleanupConfig)
+	if err != nil {
+		return nil, err
+	}
+	plan := &CleanupPlan{protection: protection, filter: filter, now: time.Now(), dates: c.cleanupDates(cleanupConfig)}
+	if cleanupConfig.QuarantinePeriod != "" {
+		if plan.quarantinePeriod, err = ParseDuration(cleanupConfig.QuarantinePeriod); err != nil {
+			return nil, fmt.Errorf("invalid quarantinePeriod: %w", err)
+		}
+	}
+	plan.rescuePeriod = defaultRescuePeriod
+	if cleanupConfig.RescuePeriod != "" {
+		if plan.rescuePeriod, err = ParseDuration(cleanupConfig.RescuePeriod); err != nil {
+			return nil, fmt.Errorf("invalid rescuePeriod: %w", err)
+		}
+	}
+	if cleanupConfig.WarnBefore != "" {
+		if plan.warnBefore, err = ParseDuration(cleanupConfig.WarnBefore); err != nil {
+			return nil, fmt.Errorf("invalid warnBefore: %w", err)
+		}
+	}
+	if cleanupConfig.Paging != nil || cleanupConfig.DeletesPerSecond > 0 {
+		plan.setPaging(cleanupConfig, resume, c.deletionBudget(cleanupConfig))
 	}
 
-	// Use default namespace if none specified
-	namespaces := cleanupConfig.Namespaces
-	if len(namespaces) == 0 {
-		namespaces = []string{defaultNamespace}
+	resolved, err := c.ResolveNamespaces(ctx, cleanupConfig, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(resolved))
+	for _, namespace := range resolved {
+		if reason := protection.namespaceReason(namespace); reason != "" {
+			plan.skip("Namespace", &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, reason)
+			continue
+		}
+		namespaces = append(namespaces, namespace)
 	}
 
-	var totalDeleted int32
+	// Whole namespaces go first; their resources don't need to be cleaned up one by one
+	if cleanupConfig.DeleteNamespace != nil {
+		namespaces = c.planNamespaces(ctx, namespaces, cleanupConfig, defaultNamespace, plan)
+	}
+	plan.namespaces = namespaces
 
+	if plan.resume != nil && (!slices.Contains(cleanupConfig.ResourceTypes, plan.resume.ResourceType) || !slices.Contains(namespaces, plan.resume.Namespace)) {
+		logger.Info("Cleanup continuation no longer matches the configuration, starting over",
+			"type", plan.resume.ResourceType, "namespace", plan.resume.Namespace)
+		plan.restart()
+	}
 	for _, resourceType := range cleanupConfig.ResourceTypes {
 		for _, namespace := range namespaces {
-			deleted, err := c.cleanupResourceType(ctx, resourceType, namespace, cleanupConfig)
-			if err != nil {
-				logger.Error(err, "Failed to cleanup resource type", "type", resourceType, "namespace", namespace)
+			if plan.skipUntilResumed(resourceType, namespace) {
 				continue
 			}
-			totalDeleted += deleted
+			if err := c.planResourceType(ctx, resourceType, namespace, cleanupConfig, plan); err != nil {
+				logger.Error(err, "Failed to cleanup resource type", "type", resourceType, "namespace", namespace)
+			}
+			if plan.Continuation != nil {
+				logger.Info("Cleanup paused until the next reconcile", "pages", plan.pages, "deletions", len(plan.Deletions),
+					"type", resourceType, "namespace", namespace)
+				return plan, nil
+			}
 		}
 	}
 
-	logger.Info("Cleanup operation completed", "totalDeleted", totalDeleted, "dryRun", cleanupConfig.DryRun)
-	return totalDeleted, nil
+	if cleanupConfig.UnreferencedConfigs != nil {
+		for _, namespace := range namespaces {
+			if err := c.planUnreferencedConfigs(ctx, namespace, cleanupConfig, plan); err != nil {
+				logger.Error(err, "Failed to cleanup unreferenced ConfigMaps and Secrets", "namespace", namespace)
+			}
+		}
+	}
+
+	logger.Info("Cleanup planned", "deletions", len(plan.Deletions), "matched", plan.Matched, "skipped", len(plan.Skipped))
+	return plan, nil
 }
 
-// cleanupResourceType handles cleanup for a specific resource type in a namespace
-func (c *K8sClient) cleanupResourceType(ctx context.Context, resourceType, namespace string, cleanupConfig *cronschedulesv1.CleanupConfig) (int32, error) {
-	objList, err := c.createResourceList(resourceType)
-	if err != nil {
-		return 0, err
+// ExecuteCleanupPlan deletes the planned resources
+func (c *K8sClient) ExecuteCleanupPlan(ctx context.Context, plan *CleanupPlan, cleanupConfig *cronschedulesv1.CleanupConfig) CleanupResult {
+	logger := log.FromContext(ctx)
+	result := CleanupResult{Skipped: plan.Skipped, QuarantinedResources: plan.Quarantined, UpcomingDeletions: plan.upcomingDeletions()}
+
+	for _, pending := range plan.Upcoming {
+		c.notifyPendingDeletion(ctx, pending, cleanupConfig)
+	}
+	for _, obj := range plan.Withdrawn {
+		c.withdrawPendingDeletion(ctx, obj, cleanupConfig)
 	}
 
-	listOpts := c.buildListOptions(resourceType, namespace, cleanupConfig)
+	for _, obj := range plan.Rescues {
+		c.rescueResource(ctx, obj, cleanupConfig)
+	}
+	for _, obj := range plan.Quarantines {
+		kind, reason := objectKind(obj), plan.reasons[obj]
+		if err := c.quarantineResource(ctx, obj, cleanupConfig, plan.now.Add(plan.quarantinePeriod)); err != nil {
+			result.Failed++
+			result.record(kind, obj, cronschedulesv1.CleanupActionFailed, reason, "", err)
+			continue
+		}
+		result.Quarantined++
+		action := cronschedulesv1.CleanupActionQuarantined
+		if cleanupConfig.DryRun {
+			action = cronschedulesv1.CleanupActionWouldQuarantine
+		}
+		result.record(kind, obj, action, reason, "", nil)
+	}
+	for _, obj := range plan.Deletions {
+		if _, ok := plan.groupOf[obj]; ok {
+			// Deleted with their group
+			continue
+		}
+		if group, ok := plan.groups[obj]; ok {
+			c.deleteGroup(ctx, plan, obj, group, cleanupConfig, &result)
+			continue
+		}
+		_ = c.executeDeletion(ctx, plan, obj, "", cleanupConfig, &result)
+	}
+	for _, skipped := range plan.Skipped {
+		if len(result.Records) >= maxSkippedResources {
+			result.Truncated++
+			continue
+		}
+		result.Records = append(result.Records, cronschedulesv1.CleanupRecord{
+			Kind:      skipped.Kind,
+			Namespace: skipped.Namespace,
+			Name:      skipped.Name,
+			Action:    cronschedulesv1.CleanupActionSkipped,
+			Reason:    skipped.Reason,
+		})
+	}
+
+	logger.Info("Cleanup operation completed", "totalDeleted", result.Deleted, "quarantined", result.Quarantined, "failed", result.Failed,
+		"rescued", len(plan.Rescues), "skipped", len(result.Skipped), "dryRun", cleanupConfig.DryRun)
+	return result
+}
 
-	// List resources
-	if err := c.List(ctx, objList, listOpts...); err != nil {
-		return 0, fmt.Errorf("failed to list %s in namespace %s: %w", resourceType, namespace, err)
-	}
-
-	return c.processResourceList(ctx, objList, cleanupConfig), nil
-}
-
-// createResourceList creates the appropriate list object for the resource type
-func (c *K8sClient) createResourceList(resourceType string) (client.ObjectList, error) {
-	switch resourceType {
-	case "Deployment":
-		return &appsv1.DeploymentList{}, nil
-	case "StatefulSet":
-		return &appsv1.StatefulSetList{}, nil
-	case "Service":
-		return &corev1.ServiceList{}, nil
-	case "ConfigMap":
-		return &corev1.ConfigMapList{}, nil
-	case "Secret":
-		return &corev1.SecretList{}, nil
-	case "Pod":
-		return &corev1.PodList{}, nil
-	case "Job":
-		return &batchv1.JobList{}, nil
-	case "Role":
-		return &rbacv1.RoleList{}, nil
-	case "RoleBinding":
-		return &rbacv1.RoleBindingList{}, nil
-	case "ClusterRole":
-		return &rbacv1.ClusterRoleList{}, nil
-	case "ClusterRoleBinding":
-		return &rbacv1.ClusterRoleBindingList{}, nil
+// executeDeletion deletes a planned resource and records the outcome in the result
+func (c *K8sClient) executeDeletion(ctx context.Context, plan *CleanupPlan, obj client.Object, group string, cleanupConfig *cronschedulesv1.CleanupConfig, result *CleanupResult) error {
+	kind, reason := objectKind(obj), plan.reasons[obj]
+	deleted, err := c.deleteResource(ctx, obj, cleanupConfig)
+	switch {
+	case errors.Is(err, errDeletionPending):
+		if len(result.PendingDeletions) >= maxSkippedResources {
+			// Beyond the tracked ones, an accepted deletion counts as done
+			result.Deleted++
+			result.record(kind, obj, cronschedulesv1.CleanupActionDeleted, reason, group, nil)
+			return nil
+		}
+		pending, err := c.pendingDeletion(obj, plan.now.Add(deletionTimeout(cleanupConfig)))
+		if err != nil {
+			result.Failed++
+			result.record(kind, obj, cronschedulesv1.CleanupActionFailed, reason, group, err)
+			return err
+		}
+		result.PendingDeletions = append(result.PendingDeletions, pending)
+		result.record(kind, obj, cronschedulesv1.CleanupActionDeleting, reason, group, nil)
+		return nil
+	case err != nil:
+		result.Failed++
+		result.record(kind, obj, cronschedulesv1.CleanupActionFailed, reason, group, err)
+	case deleted == 0:
+		// Already gone
+	case cleanupConfig.DryRun:
+		result.Deleted++
+		result.record(kind, obj, cronschedulesv1.CleanupActionWouldDelete, reason, group, nil)
 	default:
-		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+		result.Deleted++
+		result.record(kind, obj, cronschedulesv1.CleanupActionDeleted, reason, group, nil)
 	}
+	return err
 }
 
-// buildListOptions builds the list options for querying resources
-func (c *K8sClient) buildListOptions(resourceType, namespace string, cleanupConfig *cronschedulesv1.CleanupConfig) []client.ListOption {
-	listOpts := []client.ListOption{}
+// deleteGroup deletes the related objects of a group, then its primary resource. The first failure stops the
+// group: the remaining objects, including the primary resource, are reported as failed and retried by the next run.
+func (c *K8sClient) deleteGroup(ctx context.Context, plan *CleanupPlan, primary client.Object, cleanup *cleanupGroup, cleanupConfig *cronschedulesv1.CleanupConfig, result *CleanupResult) {
+	group := cleanup.name
+	members := append(append([]client.Object{}, cleanup.related...), primary)
 
-	// Namespace scoping: ClusterRole and ClusterRoleBinding are cluster-scoped
-	if resourceType != "ClusterRole" && resourceType != "ClusterRoleBinding" {
-		listOpts = append(listOpts, client.InNamespace(namespace))
+	for i, obj := range members {
+		err := c.executeDeletion(ctx, plan, obj, group, cleanupConfig, result)
+		if err == nil {
+			continue
+		}
+		failed := fmt.Errorf("not deleted: %s %s of the same group failed", objectKind(obj), obj.GetName())
+		for _, rest := range members[i+1:] {
+			result.Failed++
+			result.record(objectKind(rest), rest, cronschedulesv1.CleanupActionFailed, plan.reasons[rest], group, failed)
+		}
+		c.recordEvent(primary, corev1.EventTypeWarning, cleanup.failureEvent,
+			fmt.Sprintf("Cleanup of %s and its related objects stopped: %s %s: %v", group, objectKind(obj), obj.GetName(), err))
+		return
+	}
+}
+
+// planResourceType plans the cleanup of a specific resource type in a namespace
+func (c *K8sClient) planResourceType(ctx context.Context, resourceType, namespace string, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) error {
+	if resourceType == HelmReleaseResourceType {
+		return c.planHelmReleases(ctx, namespace, cleanupConfig, plan)
 	}
 
-	// Add label selector if specified
-	if len(cleanupConfig.LabelSelector) > 0 {
-		selector := labels.SelectorFromSet(cleanupConfig.LabelSelector)
-		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
+	newList, namespaced, err := c.resourceListFactory(resourceType)
+	if err != nil {
+		return err
+	}
+	listOpts := c.buildListOptions(namespaced, namespace, plan.filter)
+	if plan.pageSize > 0 {
+		return c.planPages(ctx, resourceType, namespace, newList, listOpts, cleanupConfig, plan)
+	}
+
+	// List resources
+	objList := newList()
+	if err := c.reader(plan.filter).List(ctx, objList, listOpts...); err != nil {
+		return fmt.Errorf("failed to list %s in namespace %s: %w", resourceType, namespace, err)
 	}
 
-	return listOpts
+	c.planItems(ctx, c.extractItemsFromList(objList), cleanupConfig, plan)
+	return nil
 }
 
-// processResourceList processes a list of resources and returns the count of deleted resources
-func (c *K8sClient) processResourceList(ctx context.Context, objList client.ObjectList, cleanupConfig *cronschedulesv1.CleanupConfig) int32 {
-	var deleted int32
+// buildListOptions builds the list options for querying resources
+func (c *K8sClient) buildListOptions(namespaced bool, namespace string, filter *ResourceFilter) []client.ListOption {
+	listOpts := []client.ListOption{}
 
-	// Process each resource based on type using reflection to avoid repetitive code
-	items := c.extractItemsFromList(objList)
-	for _, item := range items {
-		if c.shouldCleanupResource(ctx, item, cleanupConfig) {
-			deleted += c.deleteResource(ctx, item, cleanupConfig.DryRun)
-		}
+	// Namespace scoping: cluster-scoped resources are listed across the cluster
+	if namespaced {
+		listOpts = append(listOpts, client.InNamespace(namespace))
 	}
 
-	return deleted
+	// Label and field selectors are applied by the list itself
+	return append(listOpts, filter.listOptions()...)
 }
 
-// extractItemsFromList extracts items from different list types
-func (c *K8sClient) extractItemsFromList(objList client.ObjectList) []client.Object {
-	var items []client.Object
-
-	switch list := objList.(type) {
-	case *appsv1.DeploymentList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *appsv1.StatefulSetList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *corev1.ServiceList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *corev1.ConfigMapList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *corev1.SecretList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *corev1.PodList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *batchv1.JobList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
-		}
-	case *rbacv1.RoleList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
+// planItems adds the resources due for cleanup to the plan
+func (c *K8sClient) planItems(ctx context.Context, items []client.Object, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) {
+	for _, item := range items {
+		// Released resources are restored whether or not they still match
+		if state, _ := quarantineStateOf(item); state == released {
+			plan.rescue(item)
+			continue
 		}
-	case *rbacv1.RoleBindingList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
+		if !plan.filter.matchesName(item.GetName()) || !matchesStateFilters(item, cleanupConfig) {
+			continue
 		}
-	case *rbacv1.ClusterRoleList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
+		plan.Matched++
+		if c.shouldCleanupResource(ctx, item, cleanupConfig) {
+			reason := cleanupReason(item, cleanupConfig.AnnotationKey)
+			if cleanupConfig.Cascade != nil {
+				c.planCascade(ctx, item, reason, cleanupConfig, plan)
+				continue
+			}
+			plan.add(item, reason)
+			continue
 		}
-	case *rbacv1.ClusterRoleBindingList:
-		for i := range list.Items {
-			items = append(items, &list.Items[i])
+		if plan.warnBefore > 0 {
+			plan.checkUpcoming(item, cleanupConfig.AnnotationKey, c.ageReference(ctx, item, cleanupConfig.AgeSource))
 		}
 	}
-
-	return items
 }
 
-// deleteResource handles the actual deletion or dry-run logging
-func (c *K8sClient) deleteResource(ctx context.Context, obj client.Object, dryRun bool) int32 {
+// deleteResource handles the actual deletion or dry-run logging. It returns 1 if the resource was deleted
+// (or would be in dry-run mode), 0 if it was already gone, and an error if it couldn't be deleted.
+func (c *K8sClient) deleteResource(ctx context.Context, obj client.Object, cleanupConfig *cronschedulesv1.CleanupConfig) (int32, error) {
 	logger := log.FromContext(ctx)
 
-	// Get resource type from the object type
+	// Get resource type from the object kind, falling back to the Go type
 	resourceType := fmt.Sprintf("%T", obj)
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		resourceType = kind
+	}
 
-	if dryRun {
+	if cleanupConfig.DryRun {
 		logger.Info("DRY RUN: Would delete resource",
+			"type", resourceType,
+			"name", obj.GetName(),
+			"namespace", obj.GetNamespace(),
+			"propagationPolicy", cleanupConfig.PropagationPolicy)
+		return 1, nil
+	}
+
+	if c.DeleteLimiter != nil && cleanupConfig.DeletesPerSecond > 0 {
+		// Takes a token without waiting: the run planned no more deletions than the limiter had tokens for,
+		// and the deletions of a page that went over delay the next part of the run
+		c.DeleteLimiter.Reserve()
+	}
+	if err := c.Delete(ctx, obj, deleteOptions(cleanupConfig)...); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Already deleted, e.g. by an earlier pass of the same run
+			return 0, nil
+		}
+		logger.Error(err, "Failed to delete resource",
 			"type", resourceType,
 			"name", obj.GetName(),
 			"namespace", obj.GetNamespace())
-		return 1
-	} else {
-		if err := c.Delete(ctx, obj); err != nil {
-			logger.Error(err, "Failed to delete resource",
+		return 0, err
+	}
+
+	if cleanupConfig.WaitForDeletion {
+		exists, err := c.DeletionPending(ctx, obj)
+		if err != nil || exists {
+			logger.Info("Waiting for resource to disappear",
 				"type", resourceType,
 				"name", obj.GetName(),
 				"namespace", obj.GetNamespace())
-			return 0
+			return 0, errDeletionPending
 		}
-		logger.Info("Successfully deleted resource",
-			"type", resourceType,
-			"name", obj.GetName(),
-			"namespace", obj.GetNamespace())
-		return 1
 	}
+
+	logger.Info("Successfully deleted resource",
+		"type", resourceType,
+		"name", obj.GetName(),
+		"namespace", obj.GetNamespace())
+	return 1, nil
+}
+
+// deleteOptions builds the delete options from the cleanup configuration
+func deleteOptions(cleanupConfig *cronschedulesv1.CleanupConfig) []client.DeleteOption {
+	var opts []client.DeleteOption
+	if cleanupConfig.PropagationPolicy != "" {
+		opts = append(opts, client.PropagationPolicy(metav1.DeletionPropagation(cleanupConfig.PropagationPolicy)))
+	}
+	if cleanupConfig.GracePeriodSeconds != nil {
+		opts = append(opts, client.GracePeriodSeconds(*cleanupConfig.GracePeriodSeconds))
+	}
+	return opts
+}
+
+func deletionTimeout(cleanupConfig *cronschedulesv1.CleanupConfig) time.Duration {
+	if timeout, err := ParseDuration(cleanupConfig.DeletionTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultDeletionTimeout
+}
+
+// pendingDeletion describes a deleted resource still to disappear by the deadline
+func (c *K8sClient) pendingDeletion(obj client.Object, deadline time.Time) (cronschedulesv1.PendingDeletion, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return cronschedulesv1.PendingDeletion{}, err
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return cronschedulesv1.PendingDeletion{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		UID:        obj.GetUID(),
+		Deadline:   metav1.NewTime(deadline),
+	}, nil
+}
+
+// DeletionPending reports whether a deleted object still exists. An object of the same name created since
+// then, with another UID, doesn't count. It is read from the API server to avoid caching the object's kind.
+func (c *K8sClient) DeletionPending(ctx context.Context, obj client.Object) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return false, err
+	}
+	current := &metav1.PartialObjectMetadata{}
+	current.SetGroupVersionKind(gvk)
+	if err := c.uncachedReader().Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return obj.GetUID() == "" || current.GetUID() == obj.GetUID(), nil
+}
+
+// PendingDeletionObject returns an object identifying a pending deletion for DeletionPending
+func PendingDeletionObject(pending cronschedulesv1.PendingDeletion) client.Object {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(pending.APIVersion, pending.Kind))
+	obj.SetNamespace(pending.Namespace)
+	obj.SetName(pending.Name)
+	obj.SetUID(pending.UID)
+	return obj
+}
+
+// matchesStateFilters applies the Pod phase and Job condition filters of the cleanup configuration.
+// Resources of other kinds always match.
+func matchesStateFilters(obj client.Object, cleanupConfig *cronschedulesv1.CleanupConfig) bool {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		if len(cleanupConfig.PodPhases) == 0 {
+			return true
+		}
+		for _, phase := range cleanupConfig.PodPhases {
+			if phase == cronschedulesv1.PodPhaseEvicted {
+				if o.Status.Phase == corev1.PodFailed && o.Status.Reason == "Evicted" {
+					return true
+				}
+				continue
+			}
+			if string(o.Status.Phase) == phase {
+				return true
+			}
+		}
+		return false
+	case *batchv1.Job:
+		if len(cleanupConfig.JobConditions) == 0 {
+			return true
+		}
+		for _, condition := range o.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			for _, wanted := range cleanupConfig.JobConditions {
+				if string(condition.Type) == wanted {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
 }
 
 // shouldCleanupResource determines if a resource should be cleaned up based on annotations or orphan rules
@@ -487,7 +815,7 @@ func (c *K8sClient) shouldCleanupResource(ctx context.Context, obj client.Object
 		}
 
 		// Parse cleanup time/duration
-		return c.isCleanupTimeReached(ctx, cleanupValue, obj)
+		return c.isCleanupTimeReached(ctx, cleanupValue, obj, c.ageReference(ctx, obj, cleanupConfig.AgeSource), c.cleanupDates(cleanupConfig))
 	}
 
 	// Handle orphan resources (new logic)
@@ -504,81 +832,132 @@ func (c *K8sClient) isOrphanResourceForCleanup(ctx context.Context, obj client.O
 	logger := log.FromContext(ctx)
 
 	// Parse the max age duration
-	maxAge, err := time.ParseDuration(cleanupConfig.OrphanResourceMaxAge)
+	maxAge, err := ParseDuration(cleanupConfig.OrphanResourceMaxAge)
 	if err != nil {
 		logger.Error(err, "Invalid orphan resource max age format", "maxAge", cleanupConfig.OrphanResourceMaxAge)
 		return false
 	}
 
 	// Calculate if resource is old enough to be considered orphan
-	now := time.Now()
-	resourceAge := now.Sub(obj.GetCreationTimestamp().Time)
+	since := c.ageReference(ctx, obj, cleanupConfig.AgeSource)
+	resourceAge := time.Since(since)
 
-	if resourceAge > maxAge {
-		logger.Info("Orphan resource cleanup time reached",
+	if resourceAge <= maxAge {
+		logger.V(1).Info("Orphan resource not old enough for cleanup",
 			"name", obj.GetName(),
 			"namespace", obj.GetNamespace(),
 			"age", resourceAge,
-			"maxAge", maxAge,
-			"created", obj.GetCreationTimestamp().Time)
-		return true
+			"maxAge", maxAge)
+		return false
+	}
+
+	switch cleanupConfig.OrphanDetection {
+	case cronschedulesv1.OrphanDetectionOwnerReferences:
+		if !c.hasMissingOwner(ctx, obj) {
+			logger.V(1).Info("Resource has no missing owner, not an orphan",
+				"name", obj.GetName(),
+				"namespace", obj.GetNamespace())
+			return false
+		}
+	case cronschedulesv1.OrphanDetectionUnreferenced:
+		if !c.isUnreferenced(ctx, obj) {
+			logger.V(1).Info("Resource is referenced, not an orphan",
+				"name", obj.GetName(),
+				"namespace", obj.GetNamespace())
+			return false
+		}
 	}
 
-	logger.V(1).Info("Orphan resource not old enough for cleanup",
+	logger.Info("Orphan resource cleanup time reached",
 		"name", obj.GetName(),
 		"namespace", obj.GetNamespace(),
 		"age", resourceAge,
-		"maxAge", maxAge)
-
-	return false
+		"maxAge", maxAge,
+		"detection", cleanupConfig.OrphanDetection,
+		"ageSource", cleanupConfig.AgeSource,
+		"since", since)
+	return true
 }
 
-// isCleanupTimeReached checks if the cleanup time has been reached
-func (c *K8sClient) isCleanupTimeReached(ctx context.Context, cleanupValue string, obj client.Object) bool {
+// isCleanupTimeReached checks if the cleanup time has been reached; durations count from since
+func (c *K8sClient) isCleanupTimeReached(ctx context.Context, cleanupValue string, obj client.Object, since time.Time, dates cleanupDates) bool {
 	logger := log.FromContext(ctx)
-	now := time.Now()
-
-	// Try to parse as duration (e.g., "24h", "7d")
-	if duration, err := time.ParseDuration(cleanupValue); err == nil {
-		// Use creation time + duration
-		cleanupTime := obj.GetCreationTimestamp().Add(duration)
-		if now.After(cleanupTime) {
-			logger.Info("Resource cleanup time reached (duration-based)",
-				"name", obj.GetName(),
-				"created", obj.GetCreationTimestamp().Time,
-				"duration", cleanupValue,
-				"cleanupTime", cleanupTime)
-			return true
-		}
-		return false
-	}
 
-	// Try to parse as absolute time (RFC3339)
-	if cleanupTime, err := time.Parse(time.RFC3339, cleanupValue); err == nil {
-		if now.After(cleanupTime) {
-			logger.Info("Resource cleanup time reached (absolute time)",
-				"name", obj.GetName(),
-				"cleanupTime", cleanupTime)
-			return true
-		}
+	cleanupTime, err := cleanupDeadline(cleanupValue, since, dates)
+	if err != nil {
+		logger.Error(nil, "Invalid cleanup time format",
+			"name", obj.GetName(),
+			"value", cleanupValue,
+			"supportedFormats", cleanupTimeFormats)
+		c.recordEvent(obj, corev1.EventTypeWarning, "InvalidCleanupAnnotation",
+			fmt.Sprintf("cleanup annotation value %q is not a valid %s; resource will not be cleaned up", cleanupValue, cleanupTimeFormats))
 		return false
 	}
 
-	// Try to parse as simple date format
-	if cleanupTime, err := time.Parse("2006-01-02", cleanupValue); err == nil {
-		if now.After(cleanupTime) {
-			logger.Info("Resource cleanup time reached (date-based)",
-				"name", obj.GetName(),
-				"cleanupTime", cleanupTime)
-			return true
-		}
+	if !time.Now().After(cleanupTime) {
 		return false
 	}
-
-	logger.Error(nil, "Invalid cleanup time format",
+	logger.Info("Resource cleanup time reached",
 		"name", obj.GetName(),
+		"namespace", obj.GetNamespace(),
 		"value", cleanupValue,
-		"supportedFormats", "duration (24h, 7d), RFC3339 (2006-01-02T15:04:05Z07:00), or date (2006-01-02)")
+		"cleanupTime", cleanupTime)
+	return true
+}
 
-	return false
+// localTime formats t in the time zone of the CronJobScaleDown, for messages
+func (c *K8sClient) localTime(t time.Time) string {
+	if c.Location == nil {
+		return t.UTC().Format(DisplayTimeLayout)
+	}
+	return t.In(c.Location).Format(DisplayTimeLayout)
+}
+
+// cleanupDates holds how cleanup annotation dates and times without an offset are resolved
+type cleanupDates struct {
+	// location is the time zone they are interpreted in
+	location *time.Location
+	// endOfDay makes dates expire at the end of the day instead of its start
+	endOfDay bool
+}
+
+// cleanupDates returns how the cleanup annotations of the cleanup configuration are resolved
+func (c *K8sClient) cleanupDates(cleanupConfig *cronschedulesv1.CleanupConfig) cleanupDates {
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return cleanupDates{location: location, endOfDay: cleanupConfig.DateDeadline == cronschedulesv1.DateDeadlineEndOfDay}
+}
+
+// cleanupDeadline returns the time a resource is due for cleanup according to the cleanup annotation value:
+// a duration after since, an RFC3339 time, or a date. Times without an offset and dates are interpreted in
+// the time zone of dates; a date expires at the start of the day, or at its end with endOfDay.
+func cleanupDeadline(cleanupValue string, since time.Time, dates cleanupDates) (time.Time, error) {
+	location := dates.location
+	if location == nil {
+		location = time.UTC
+	}
+
+	// Duration (e.g., "24h", "7d", "1w2d") after the reference time
+	if duration, err := ParseDuration(cleanupValue); err == nil {
+		return since.Add(duration), nil
+	}
+	// Absolute time (RFC3339)
+	if cleanupTime, err := time.Parse(time.RFC3339, cleanupValue); err == nil {
+		return cleanupTime, nil
+	}
+	// Absolute time without an offset, in the configured time zone
+	if cleanupTime, err := time.ParseInLocation(noOffsetTimeLayout, cleanupValue, location); err == nil {
+		return cleanupTime, nil
+	}
+	// Simple date format, in the configured time zone
+	if day, err := time.ParseInLocation("2006-01-02", cleanupValue, location); err == nil {
+		if dates.endOfDay {
+			// AddDate keeps midnight across daylight saving time changes
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid cleanup time %q, expected %s", cleanupValue, cleanupTimeFormats)
 }
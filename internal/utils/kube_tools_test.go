@@ -2,12 +2,14 @@ package utils
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -182,3 +184,94 @@ func TestShouldCleanupResource(t *testing.T) {
 		})
 	}
 }
+
+func TestIsCleanupTimeReached(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	recorder := record.NewFakeRecorder(10)
+	k8sClient := &K8sClient{Client: fake.NewClientBuilder().Build(), Recorder: recorder}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              "test-configmap",
+		Namespace:         "default",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * Day)),
+	}}
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "7d", want: true},
+		{value: "2w", want: false},
+		{value: "1w2d12h", want: true},
+		{value: "1w3d12h", want: false},
+		{value: "2000-01-01", want: true},
+		{value: "7 days", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := k8sClient.isCleanupTimeReached(ctx, tt.value, configMap, configMap.CreationTimestamp.Time, cleanupDates{}); got != tt.want {
+				t.Errorf("isCleanupTimeReached(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected 1 event for the invalid annotation, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning InvalidCleanupAnnotation") {
+		t.Errorf("unexpected event %q", event)
+	}
+}
+
+func TestCleanupDeadlineTimeZone(t *testing.T) {
+	pacific := time.FixedZone("PST", -8*60*60)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		dates cleanupDates
+		want  time.Time
+	}{
+		{name: "date in UTC by default", value: "2025-01-20", dates: cleanupDates{}, want: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{name: "date at start of day", value: "2025-01-20", dates: cleanupDates{location: pacific}, want: time.Date(2025, 1, 20, 8, 0, 0, 0, time.UTC)},
+		{name: "date at end of day", value: "2025-01-20", dates: cleanupDates{location: pacific, endOfDay: true}, want: time.Date(2025, 1, 21, 8, 0, 0, 0, time.UTC)},
+		{name: "time without offset", value: "2025-01-20T15:30:00", dates: cleanupDates{location: pacific}, want: time.Date(2025, 1, 20, 23, 30, 0, 0, time.UTC)},
+		{name: "time with offset", value: "2025-01-20T15:30:00Z", dates: cleanupDates{location: pacific}, want: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)},
+		{name: "duration", value: "1d", dates: cleanupDates{location: pacific, endOfDay: true}, want: created.Add(Day)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanupDeadline(tt.value, created, tt.dates)
+			if err != nil {
+				t.Fatalf("cleanupDeadline(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("cleanupDeadline(%q) = %v, want %v", tt.value, got.UTC(), tt.want)
+			}
+		})
+	}
+
+	t.Run("end of day across a daylight saving change", func(t *testing.T) {
+		losAngeles, err := time.LoadLocation("America/Los_Angeles")
+		if err != nil {
+			t.Skipf("time zone database unavailable: %v", err)
+		}
+		got, err := cleanupDeadline("2025-03-09", created, cleanupDates{location: losAngeles, endOfDay: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("cleanupDeadline() = %v, want %v", got.UTC(), want)
+		}
+	})
+}
+
+// runCleanup plans and executes a cleanup run, without the deletion limits the controller checks in between
+func runCleanup(ctx context.Context, c *K8sClient, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string) (CleanupResult, error) {
+	plan, err := c.PlanCleanup(ctx, cleanupConfig, defaultNamespace, nil)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+	return c.ExecuteCleanupPlan(ctx, plan, cleanupConfig), nil
+}
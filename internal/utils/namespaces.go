@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// Namespaces are listed for namespaceSelector and deleted in deleteNamespace mode
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch;delete
+
+// protectedNamespaces are never deleted in deleteNamespace mode
+var protectedNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// NamespaceDeletableLabel set to "true" opts a namespace in to deleteNamespace. Namespaces without it are
+// cleaned up resource by resource but never deleted as a whole.
+const NamespaceDeletableLabel = "cronjob-scale-down-operator/deletable"
+
+// ResolveNamespaces returns the namespaces a cleanup runs in: the configured namespaces plus the ones
+// matching the namespace selector, or the default namespace when neither is set.
+// Namespaces being deleted are left out.
+func (c *K8sClient) ResolveNamespaces(ctx context.Context, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string) ([]string, error) {
+	namespaces := slices.Clone(cleanupConfig.Namespaces)
+
+	if selector := cleanupConfig.NamespaceSelector; selector != nil {
+		var nameRegex *regexp.Regexp
+		if selector.NameRegex != "" {
+			var err error
+			if nameRegex, err = regexp.Compile(selector.NameRegex); err != nil {
+				return nil, fmt.Errorf("invalid namespaceSelector.nameRegex: %w", err)
+			}
+		}
+
+		namespaceList := &corev1.NamespaceList{}
+		listOpts := []client.ListOption{}
+		if len(selector.MatchLabels) > 0 {
+			listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(selector.MatchLabels)})
+		}
+		if err := c.List(ctx, namespaceList, listOpts...); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+
+		for _, namespace := range namespaceList.Items {
+			if namespace.Status.Phase == corev1.NamespaceTerminating || namespace.DeletionTimestamp != nil {
+				continue
+			}
+			if nameRegex != nil && !nameRegex.MatchString(namespace.Name) {
+				continue
+			}
+			if !slices.Contains(namespaces, namespace.Name) {
+				namespaces = append(namespaces, namespace.Name)
+			}
+		}
+		return namespaces, nil
+	}
+
+	if len(namespaces) == 0 {
+		namespaces = []string{defaultNamespace}
+	}
+	return namespaces, nil
+}
+
+// planNamespaces plans the deletion of the namespaces whose TTL annotation expired or that have been
+// idle for the configured period, and returns the namespaces that are kept
+func (c *K8sClient) planNamespaces(ctx context.Context, namespaces []string, cleanupConfig *cronschedulesv1.CleanupConfig, defaultNamespace string, plan *CleanupPlan) []string {
+	logger := log.FromContext(ctx)
+
+	var idlePeriod time.Duration
+	if cleanupConfig.DeleteNamespace.IdlePeriod != "" {
+		var err error
+		if idlePeriod, err = ParseDuration(cleanupConfig.DeleteNamespace.IdlePeriod); err != nil {
+			logger.Error(err, "Invalid namespace idle period", "idlePeriod", cleanupConfig.DeleteNamespace.IdlePeriod)
+		}
+	}
+
+	kept := make([]string, 0, len(namespaces))
+	for _, name := range namespaces {
+		namespace := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+			logger.Error(err, "Failed to get namespace", "namespace", name)
+			kept = append(kept, name)
+			continue
+		}
+
+		if name == defaultNamespace || slices.Contains(protectedNamespaces, name) {
+			plan.skip("Namespace", namespace, "protected namespace")
+			kept = append(kept, name)
+			continue
+		}
+
+		if namespace.Labels[NamespaceDeletableLabel] != "true" {
+			plan.skip("Namespace", namespace, fmt.Sprintf("not labeled %s=true", NamespaceDeletableLabel))
+			kept = append(kept, name)
+			continue
+		}
+
+		if cleanupConfig.Archive != nil {
+			// Deleting the namespace would delete its contents without archiving them
+			plan.skip("Namespace", namespace, "namespace contents can't be archived")
+			kept = append(kept, name)
+			continue
+		}
+
+		plan.Matched++
+		if reason := c.namespaceExpiry(ctx, namespace, cleanupConfig.AnnotationKey, idlePeriod, plan.dates); reason != "" {
+			if protected := c.protectedContent(ctx, name, cleanupConfig.ResourceTypes, plan); protected != "" {
+				plan.skip("Namespace", namespace, protected)
+				kept = append(kept, name)
+				continue
+			}
+			plan.add(namespace, reason)
+			continue
+		}
+		plan.checkUpcoming(namespace, cleanupConfig.AnnotationKey, namespace.CreationTimestamp.Time)
+		kept = append(kept, name)
+	}
+	return kept
+}
+
+// protectedContent returns why a namespace due for deletion is kept because one of its objects is protected,
+// or an empty string. Deleting the namespace would delete every object in it, so the objects of the built-in
+// kinds and of the configured custom kinds are checked like the ones cleaned up one by one. They are listed
+// from the API server, and a namespace whose contents can't be listed is kept.
+func (c *K8sClient) protectedContent(ctx context.Context, namespace string, resourceTypes []string, plan *CleanupPlan) string {
+	if plan.protection == nil {
+		return ""
+	}
+
+	kinds := BuiltinResourceTypes()
+	for _, resourceType := range resourceTypes {
+		if resourceType != HelmReleaseResourceType && !slices.Contains(kinds, resourceType) {
+			kinds = append(kinds, resourceType)
+		}
+	}
+
+	reader := c.uncachedReader()
+	for _, resourceType := range kinds {
+		newList, namespaced, err := c.resourceListFactory(resourceType)
+		if err != nil {
+			return fmt.Sprintf("can't check %s for protected objects: %v", resourceType, err)
+		}
+		if !namespaced {
+			continue
+		}
+		list := newList()
+		if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return fmt.Sprintf("can't check %s for protected objects: %v", resourceType, err)
+		}
+		for _, obj := range c.extractItemsFromList(list) {
+			if kind, reason := plan.protection.reason(obj); reason != "" {
+				return fmt.Sprintf("contains protected %s %s: %s", kind, obj.GetName(), reason)
+			}
+		}
+	}
+	return ""
+}
+
+// namespaceExpiry returns why the namespace is due for deletion, its TTL annotation expired or it has been
+// idle for idlePeriod, or an empty string if it is kept
+func (c *K8sClient) namespaceExpiry(ctx context.Context, namespace *corev1.Namespace, annotationKey string, idlePeriod time.Duration, dates cleanupDates) string {
+	logger := log.FromContext(ctx)
+
+	if ttl, ok := namespace.GetAnnotations()[annotationKey]; ok && c.isCleanupTimeReached(ctx, ttl, namespace, namespace.CreationTimestamp.Time, dates) {
+		return cronschedulesv1.CleanupReasonAnnotation
+	}
+	if idlePeriod <= 0 {
+		return ""
+	}
+
+	lastActivity, err := c.lastWorkloadActivity(ctx, namespace)
+	if err != nil {
+		logger.Error(err, "Failed to find last workload activity, keeping namespace", "namespace", namespace.Name)
+		return ""
+	}
+	idle := time.Since(lastActivity)
+	if idle < idlePeriod {
+		return ""
+	}
+
+	logger.Info("Namespace idle period reached", "namespace", namespace.Name, "lastActivity", lastActivity, "idlePeriod", idlePeriod)
+	return cronschedulesv1.CleanupReasonIdle
+}
+
+// lastWorkloadActivity returns the last time a workload in the namespace was created or updated, or the
+// namespace's creation time if it has no workloads. A namespace with a running pod is active now. The
+// workloads are listed from the API server: a stale cache could miss the workload that keeps it active.
+func (c *K8sClient) lastWorkloadActivity(ctx context.Context, namespace *corev1.Namespace) (time.Time, error) {
+	lastActivity := namespace.CreationTimestamp.Time
+	reader := c.uncachedReader()
+
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&appsv1.DaemonSetList{},
+		&batchv1.JobList{},
+		&batchv1.CronJobList{},
+		&corev1.PodList{},
+	}
+	for _, list := range lists {
+		if err := reader.List(ctx, list, client.InNamespace(namespace.Name)); err != nil {
+			return time.Time{}, fmt.Errorf("failed to list workloads: %w", err)
+		}
+		for _, item := range c.extractItemsFromList(list) {
+			if pod, ok := item.(*corev1.Pod); ok && pod.Status.Phase == corev1.PodRunning {
+				return time.Now(), nil
+			}
+			if t := lastUpdateTime(item); t.After(lastActivity) {
+				lastActivity = t
+			}
+		}
+	}
+	return lastActivity, nil
+}
+
+// lastUpdateTime returns the latest of the object's creation time and the times of its managed fields.
+// Status updates and the operator's own changes, such as quarantine or pending-deletion notices, are not
+// activity: counting them would reset the age of the objects the operator is about to clean up.
+func lastUpdateTime(obj client.Object) time.Time {
+	last := obj.GetCreationTimestamp().Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == FieldManager || entry.Subresource == "status" {
+			continue
+		}
+		if entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	return last
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func namespace(name string, created time.Time, labels, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		CreationTimestamp: metav1.NewTime(created),
+		Labels:            labels,
+		Annotations:       annotations,
+	}}
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	preview := map[string]string{"env": "preview"}
+	terminating := namespace("pr-3", time.Now(), preview, nil)
+	terminating.Status.Phase = corev1.NamespaceTerminating
+	k8sClient := newOrphanTestClient(
+		namespace("pr-1", time.Now(), preview, nil),
+		namespace("pr-2", time.Now(), preview, nil),
+		terminating,
+		namespace("staging", time.Now(), preview, nil),
+		namespace("pr-9", time.Now(), nil, nil),
+	)
+
+	tests := []struct {
+		name   string
+		config *cronschedulesv1.CleanupConfig
+		want   []string
+	}{
+		{
+			name:   "default namespace",
+			config: &cronschedulesv1.CleanupConfig{},
+			want:   []string{"ops"},
+		},
+		{
+			name:   "static namespaces",
+			config: &cronschedulesv1.CleanupConfig{Namespaces: []string{"a", "b"}},
+			want:   []string{"a", "b"},
+		},
+		{
+			name: "labels and name regex",
+			config: &cronschedulesv1.CleanupConfig{NamespaceSelector: &cronschedulesv1.NamespaceSelector{
+				MatchLabels: preview,
+				NameRegex:   "^pr-[0-9]+$",
+			}},
+			want: []string{"pr-1", "pr-2"},
+		},
+		{
+			name: "selector adds to static namespaces",
+			config: &cronschedulesv1.CleanupConfig{
+				Namespaces:        []string{"pr-1", "shared"},
+				NamespaceSelector: &cronschedulesv1.NamespaceSelector{NameRegex: "^pr-"},
+			},
+			want: []string{"pr-1", "shared", "pr-2", "pr-9"},
+		},
+		{
+			name:   "no match",
+			config: &cronschedulesv1.CleanupConfig{NamespaceSelector: &cronschedulesv1.NamespaceSelector{NameRegex: "^feature-"}},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k8sClient.ResolveNamespaces(ctx, tt.config, "ops")
+			if err != nil {
+				t.Fatalf("ResolveNamespaces returned error: %v", err)
+			}
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupDeleteNamespace(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := time.Now().Add(-10 * Day)
+	preview := map[string]string{"env": "preview"}
+	deployment := func(namespace string, created time.Time) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, CreationTimestamp: metav1.NewTime(created)}}
+	}
+	deletable := map[string]string{"env": "preview", NamespaceDeletableLabel: "true"}
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "pr-running", CreationTimestamp: metav1.NewTime(old)},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	k8sClient := newOrphanTestClient(
+		namespace("pr-expired", time.Now(), deletable, map[string]string{"cleanup-after": "2000-01-01"}),
+		namespace("pr-idle", old, deletable, nil),
+		deployment("pr-idle", old),
+		namespace("pr-active", old, deletable, nil),
+		deployment("pr-active", time.Now().Add(-time.Hour)),
+		namespace("pr-running", old, deletable, nil),
+		deployment("pr-running", old),
+		runningPod,
+		namespace("pr-not-opted-in", old, preview, map[string]string{"cleanup-after": "2000-01-01"}),
+		namespace("pr-labeled", old, deletable, map[string]string{"cleanup-after": "2000-01-01"}),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: "pr-labeled", Labels: map[string]string{ProtectedLabel: "true"}}},
+		namespace("pr-marked", old, deletable, map[string]string{"cleanup-after": "2000-01-01"}),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "keys", Namespace: "pr-marked", Annotations: map[string]string{ProtectedKey: "true"}}},
+		namespace("pr-policy", old, deletable, map[string]string{"cleanup-after": "2000-01-01"}),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db-prod", Namespace: "pr-policy"}},
+		&cronschedulesv1.CleanupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+			Spec:       cronschedulesv1.CleanupPolicySpec{ProtectedNamePatterns: []string{"*-prod"}},
+		},
+		namespace("ops", old, deletable, nil),
+		namespace("kube-system", old, deletable, nil),
+	)
+
+	result, err := runCleanup(ctx, k8sClient, &cronschedulesv1.CleanupConfig{
+		AnnotationKey:     "cleanup-after",
+		ResourceTypes:     []string{"ConfigMap"},
+		NamespaceSelector: &cronschedulesv1.NamespaceSelector{MatchLabels: preview},
+		DeleteNamespace:   &cronschedulesv1.NamespaceDeletion{IdlePeriod: "7d"},
+	}, "ops")
+	if err != nil {
+		t.Fatalf("runCleanup returned error: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("expected 2 deleted namespaces, got %d", result.Deleted)
+	}
+
+	for name, wantDeleted := range map[string]bool{
+		"pr-expired":      true,
+		"pr-idle":         true,
+		"pr-active":       false,
+		"pr-running":      false,
+		"pr-not-opted-in": false,
+		"pr-labeled":      false,
+		"pr-marked":       false,
+		"pr-policy":       false,
+		"ops":             false,
+		"kube-system":     false,
+	} {
+		err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{})
+		if deleted := apierrors.IsNotFound(err); deleted != wantDeleted {
+			t.Errorf("namespace %s: deleted = %v, want %v (err %v)", name, deleted, wantDeleted, err)
+		}
+	}
+
+	skipped := map[string]string{}
+	for _, resource := range result.Skipped {
+		if resource.Kind == "Namespace" {
+			skipped[resource.Name] = resource.Reason
+		}
+	}
+	wantSkipped := map[string]string{
+		"kube-system":     "kube-system is always protected",
+		"ops":             "protected namespace",
+		"pr-not-opted-in": "not labeled " + NamespaceDeletableLabel + "=true",
+		"pr-labeled":      "contains protected ConfigMap seed: labeled " + ProtectedLabel + "=true",
+		"pr-marked":       "contains protected Secret keys: marked with " + ProtectedKey,
+		"pr-policy":       "contains protected Service db-prod: name pattern *-prod protected by CleanupPolicy platform",
+	}
+	if !maps.Equal(skipped, wantSkipped) {
+		t.Errorf("skipped namespaces = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestCleanupDeleteNamespaceWithArchive(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	k8sClient := newOrphanTestClient(
+		namespace("pr-expired", time.Now(), map[string]string{NamespaceDeletableLabel: "true"}, map[string]string{"cleanup-after": "2000-01-01"}),
+	)
+
+	plan, err := k8sClient.PlanCleanup(ctx, &cronschedulesv1.CleanupConfig{
+		AnnotationKey:   "cleanup-after",
+		ResourceTypes:   []string{"ConfigMap"},
+		Namespaces:      []string{"pr-expired"},
+		DeleteNamespace: &cronschedulesv1.NamespaceDeletion{},
+		Archive:         &cronschedulesv1.CleanupArchive{},
+	}, "ops", nil)
+	if err != nil {
+		t.Fatalf("PlanCleanup returned error: %v", err)
+	}
+	if len(plan.Deletions) != 0 {
+		t.Errorf("expected the namespace to be kept, got deletions %v", plan.Deletions)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Reason != "namespace contents can't be archived" {
+		t.Errorf("expected the namespace to be skipped because it can't be archived, got %v", plan.Skipped)
+	}
+}
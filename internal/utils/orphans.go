@@ -0,0 +1,341 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Objects listed to find the ConfigMaps and Secrets referenced in a namespace
+//+kubebuilder:rbac:groups=apps,resources=daemonsets;replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+
+const (
+	// helmReleaseSecretType is the type of the Secrets Helm stores releases in
+	helmReleaseSecretType corev1.SecretType = "helm.sh/release.v1"
+	// rootCAConfigMapName is published in every namespace and mounted implicitly into pods
+	rootCAConfigMapName = "kube-root-ca.crt"
+)
+
+// keptSecretTypes are the Secret types never treated as unreferenced, with the reason: they are used by
+// name from outside the namespace's pod templates, e.g. by other namespaces, ingress controllers or the kubelet
+var keptSecretTypes = map[corev1.SecretType]string{
+	corev1.SecretTypeServiceAccountToken: "service account token",
+	corev1.SecretTypeDockerConfigJson:    "image pull secret",
+	corev1.SecretTypeDockercfg:           "image pull secret",
+	corev1.SecretTypeTLS:                 "TLS secret",
+	corev1.SecretTypeBootstrapToken:      "bootstrap token",
+}
+
+// namespaceReferences is the reference graph of a namespace: it maps the names of referenced
+// ConfigMaps and Secrets to the first object found referencing them (e.g. "Deployment/api")
+type namespaceReferences struct {
+	configMaps map[string]string
+	secrets    map[string]string
+}
+
+// hasMissingOwner reports whether any of the object's owners no longer exists.
+// Objects without owner references have no missing owner.
+func (c *K8sClient) hasMissingOwner(ctx context.Context, obj client.Object) bool {
+	logger := log.FromContext(ctx)
+
+	for _, ref := range obj.GetOwnerReferences() {
+		exists, err := c.ownerExists(ctx, obj.GetNamespace(), ref)
+		if err != nil {
+			// Keep the object when its owner can't be checked
+			logger.Error(err, "Failed to check owner, not treating resource as orphan",
+				"name", obj.GetName(), "namespace", obj.GetNamespace(), "owner", ref.Name, "ownerKind", ref.Kind)
+			return false
+		}
+		if !exists {
+			logger.V(1).Info("Owner of resource no longer exists",
+				"name", obj.GetName(), "namespace", obj.GetNamespace(), "owner", ref.Name, "ownerKind", ref.Kind)
+			return true
+		}
+	}
+	return false
+}
+
+// ownerExists reports whether the object an owner reference points to still exists with the same UID
+func (c *K8sClient) ownerExists(ctx context.Context, namespace string, ref metav1.OwnerReference) (bool, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid owner apiVersion %q: %w", ref.APIVersion, err)
+	}
+	gvk := gv.WithKind(ref.Kind)
+
+	// An owner kind that isn't served, e.g. while discovery fails or its CRD is reinstalled, can't be checked:
+	// the error keeps the object
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+	}
+
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, owner); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return ref.UID == "" || owner.GetUID() == ref.UID, nil
+}
+
+// isUnreferenced reports whether obj is a ConfigMap or Secret that nothing in its namespace references.
+// Objects of other kinds, owned objects, cleanup archives, Helm release Secrets and the Secret types of
+// keptSecretTypes are never unreferenced.
+func (c *K8sClient) isUnreferenced(ctx context.Context, obj client.Object) bool {
+	logger := log.FromContext(ctx)
+
+	refs, err := c.namespaceReferences(ctx, obj.GetNamespace())
+	if err != nil {
+		logger.Error(err, "Failed to find references, not treating resource as orphan",
+			"name", obj.GetName(), "namespace", obj.GetNamespace())
+		return false
+	}
+	return c.keepReason(ctx, obj, refs) == ""
+}
+
+// keepReason returns why obj must be kept although it may look unused, or an empty string if
+// obj is a ConfigMap or Secret that nothing in its namespace references
+func (c *K8sClient) keepReason(ctx context.Context, obj client.Object, refs *namespaceReferences) string {
+	var referenced map[string]string
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		if o.Name == rootCAConfigMapName {
+			return "root CA bundle published by Kubernetes"
+		}
+		referenced = refs.configMaps
+	case *corev1.Secret:
+		if o.Type == helmReleaseSecretType {
+			return "Helm release secret"
+		}
+		if reason, ok := keptSecretTypes[o.Type]; ok {
+			return reason
+		}
+		referenced = refs.secrets
+	default:
+		return fmt.Sprintf("%T is not a ConfigMap or Secret", obj)
+	}
+
+	if owners := obj.GetOwnerReferences(); len(owners) > 0 && !c.hasMissingOwner(ctx, obj) {
+		return fmt.Sprintf("owned by %s/%s", owners[0].Kind, owners[0].Name)
+	}
+	if referrer, ok := referenced[obj.GetName()]; ok {
+		return "referenced by " + referrer
+	}
+	return ""
+}
+
+// namespaceReferences returns the ConfigMaps and Secrets referenced by pod templates, ServiceAccounts
+// and Ingress TLS in the namespace. They are listed from the API server, so that a ConfigMap or Secret a
+// workload just started using isn't deleted on a stale cached view. Results are cached for the lifetime of
+// the client.
+func (c *K8sClient) namespaceReferences(ctx context.Context, namespace string) (*namespaceReferences, error) {
+	if refs, ok := c.references[namespace]; ok {
+		return refs, nil
+	}
+
+	refs := &namespaceReferences{configMaps: map[string]string{}, secrets: map[string]string{}}
+	inNamespace := client.InNamespace(namespace)
+	reader := c.uncachedReader()
+
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		refs.addPodSpec(&pods.Items[i].Spec, "Pod/"+pods.Items[i].Name)
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := reader.List(ctx, deployments, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		refs.addPodSpec(&deployments.Items[i].Spec.Template.Spec, "Deployment/"+deployments.Items[i].Name)
+	}
+
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := reader.List(ctx, replicaSets, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		refs.addPodSpec(&replicaSets.Items[i].Spec.Template.Spec, "ReplicaSet/"+replicaSets.Items[i].Name)
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := reader.List(ctx, statefulSets, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		refs.addPodSpec(&statefulSets.Items[i].Spec.Template.Spec, "StatefulSet/"+statefulSets.Items[i].Name)
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := reader.List(ctx, daemonSets, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		refs.addPodSpec(&daemonSets.Items[i].Spec.Template.Spec, "DaemonSet/"+daemonSets.Items[i].Name)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := reader.List(ctx, jobs, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		refs.addPodSpec(&jobs.Items[i].Spec.Template.Spec, "Job/"+jobs.Items[i].Name)
+	}
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := reader.List(ctx, cronJobs, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		refs.addPodSpec(&cronJobs.Items[i].Spec.JobTemplate.Spec.Template.Spec, "CronJob/"+cronJobs.Items[i].Name)
+	}
+
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err := reader.List(ctx, serviceAccounts, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list serviceaccounts: %w", err)
+	}
+	for _, sa := range serviceAccounts.Items {
+		referrer := "ServiceAccount/" + sa.Name
+		for _, secret := range sa.Secrets {
+			addReference(refs.secrets, secret.Name, referrer)
+		}
+		for _, secret := range sa.ImagePullSecrets {
+			addReference(refs.secrets, secret.Name, referrer)
+		}
+	}
+
+	ingresses := &networkingv1.IngressList{}
+	if err := reader.List(ctx, ingresses, inNamespace); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingresses.Items {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				addReference(refs.secrets, tls.SecretName, "Ingress/"+ingress.Name)
+			}
+		}
+	}
+
+	if c.references == nil {
+		c.references = map[string]*namespaceReferences{}
+	}
+	c.references[namespace] = refs
+	return refs, nil
+}
+
+// addReference records the first referrer of a name
+func addReference(references map[string]string, name, referrer string) {
+	if _, ok := references[name]; !ok {
+		references[name] = referrer
+	}
+}
+
+// addPodSpec records the ConfigMaps and Secrets a pod spec references through volumes,
+// environment variables and image pull secrets
+func (r *namespaceReferences) addPodSpec(spec *corev1.PodSpec, referrer string) {
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			addReference(r.configMaps, volume.ConfigMap.Name, referrer)
+		}
+		if volume.Secret != nil {
+			addReference(r.secrets, volume.Secret.SecretName, referrer)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					addReference(r.configMaps, source.ConfigMap.Name, referrer)
+				}
+				if source.Secret != nil {
+					addReference(r.secrets, source.Secret.Name, referrer)
+				}
+			}
+		}
+		if name := volumeSecretName(&volume.VolumeSource); name != "" {
+			addReference(r.secrets, name, referrer)
+		}
+	}
+
+	for _, secret := range spec.ImagePullSecrets {
+		addReference(r.secrets, secret.Name, referrer)
+	}
+
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container{Env: container.Env, EnvFrom: container.EnvFrom})
+	}
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				addReference(r.configMaps, envFrom.ConfigMapRef.Name, referrer)
+			}
+			if envFrom.SecretRef != nil {
+				addReference(r.secrets, envFrom.SecretRef.Name, referrer)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				addReference(r.configMaps, env.ValueFrom.ConfigMapKeyRef.Name, referrer)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				addReference(r.secrets, env.ValueFrom.SecretKeyRef.Name, referrer)
+			}
+		}
+	}
+}
+
+// volumeSecretName returns the Secret a volume source other than secret and projected volumes refers to,
+// such as the credentials of a CSI driver or of network storage, or an empty string
+func volumeSecretName(source *corev1.VolumeSource) string {
+	var ref *corev1.LocalObjectReference
+	switch {
+	case source.CSI != nil:
+		ref = source.CSI.NodePublishSecretRef
+	case source.CephFS != nil:
+		ref = source.CephFS.SecretRef
+	case source.RBD != nil:
+		ref = source.RBD.SecretRef
+	case source.ISCSI != nil:
+		ref = source.ISCSI.SecretRef
+	case source.FlexVolume != nil:
+		ref = source.FlexVolume.SecretRef
+	case source.Cinder != nil:
+		ref = source.Cinder.SecretRef
+	case source.ScaleIO != nil:
+		ref = source.ScaleIO.SecretRef
+	case source.StorageOS != nil:
+		ref = source.StorageOS.SecretRef
+	case source.AzureFile != nil:
+		return source.AzureFile.SecretName
+	}
+	if ref == nil {
+		return ""
+	}
+	return ref.Name
+}
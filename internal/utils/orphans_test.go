@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func newOrphanTestClient(objs ...client.Object) *K8sClient {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = autoscalingv2.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+	_ = rbacv1.AddToScheme(scheme)
+	_ = cronschedulesv1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion})
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Service"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(objs...).Build()
+	return &K8sClient{Client: fakeClient}
+}
+
+func TestOrphanDetectionOwnerReferences(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+	owner := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "live-uid"}}
+	k8sClient := newOrphanTestClient(owner)
+
+	configMap := func(ownerName string, uid string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", CreationTimestamp: old}}
+		if ownerName != "" {
+			cm.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: ownerName, UID: k8stypes.UID(uid)}}
+		}
+		return cm
+	}
+	unservedOwner := configMap("", "")
+	unservedOwner.OwnerReferences = []metav1.OwnerReference{{APIVersion: "example.com/v1", Kind: "Widget", Name: "w", UID: "widget-uid"}}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		CleanupOrphanResources: true,
+		OrphanResourceMaxAge:   "24h",
+		OrphanDetection:        cronschedulesv1.OrphanDetectionOwnerReferences,
+	}
+
+	tests := []struct {
+		name string
+		obj  client.Object
+		want bool
+	}{
+		{name: "no owner", obj: configMap("", ""), want: false},
+		{name: "live owner", obj: configMap("api", "live-uid"), want: false},
+		{name: "deleted owner", obj: configMap("gone", "gone-uid"), want: true},
+		{name: "recreated owner", obj: configMap("api", "old-uid"), want: true},
+		{name: "owner kind not served", obj: unservedOwner, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := k8sClient.isOrphanResourceForCleanup(ctx, tt.obj, cleanupConfig); got != tt.want {
+				t.Errorf("isOrphanResourceForCleanup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrphanDetectionUnreferenced(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name:         "config",
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "mounted"}}},
+					}},
+					Containers: []corev1.Container{{
+						Name: "app",
+						EnvFrom: []corev1.EnvFromSource{{
+							SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-secret"}},
+						}},
+					}},
+				},
+			},
+		},
+	}
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "report",
+				Env: []corev1.EnvVar{{Name: "KEY", ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "cron-config"}, Key: "k"},
+				}}},
+			}}},
+		}}}},
+	}
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{SecretName: "web-tls"}}},
+	}
+	k8sClient := newOrphanTestClient(deployment, cronJob, serviceAccount, ingress)
+
+	configMap := func(name string) client.Object {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: old}}
+	}
+	secret := func(name string, secretType corev1.SecretType) client.Object {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: old}, Type: secretType}
+	}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		CleanupOrphanResources: true,
+		OrphanResourceMaxAge:   "24h",
+		OrphanDetection:        cronschedulesv1.OrphanDetectionUnreferenced,
+	}
+
+	tests := []struct {
+		name string
+		obj  client.Object
+		want bool
+	}{
+		{name: "volume configmap", obj: configMap("mounted"), want: false},
+		{name: "cronjob env configmap", obj: configMap("cron-config"), want: false},
+		{name: "root CA configmap", obj: configMap(rootCAConfigMapName), want: false},
+		{name: "unreferenced configmap", obj: configMap("stale"), want: true},
+		{name: "envFrom secret", obj: secret("env-secret", corev1.SecretTypeOpaque), want: false},
+		{name: "image pull secret", obj: secret("registry", corev1.SecretTypeDockerConfigJson), want: false},
+		{name: "ingress TLS secret", obj: secret("web-tls", corev1.SecretTypeTLS), want: false},
+		{name: "helm release secret", obj: secret("sh.helm.release.v1.api.v1", helmReleaseSecretType), want: false},
+		{name: "unreferenced secret", obj: secret("stale", corev1.SecretTypeOpaque), want: true},
+		{name: "other kinds are never unreferenced", obj: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default", CreationTimestamp: old}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := k8sClient.isOrphanResourceForCleanup(ctx, tt.obj, cleanupConfig); got != tt.want {
+				t.Errorf("isOrphanResourceForCleanup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const (
+	// defaultPageSize applies when paging doesn't set pageSize
+	defaultPageSize = 500
+	// defaultMaxPagesPerRun applies when paging doesn't set maxPagesPerRun
+	defaultMaxPagesPerRun = 10
+)
+
+// NewDeleteLimiter returns the limiter of the deletions of cleanup runs with deletesPerSecond, holding up
+// to a second's worth of deletions
+func NewDeleteLimiter(deletesPerSecond int32) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(deletesPerSecond), max(1, int(deletesPerSecond)))
+}
+
+// DeletionDelay returns how long until the limiter allows another deletion
+func DeletionDelay(limiter *rate.Limiter) time.Duration {
+	reservation := limiter.Reserve()
+	defer reservation.Cancel()
+	return reservation.Delay()
+}
+
+// deletionBudget returns the deletions a run may plan in this reconcile under deletesPerSecond, 0 when
+// deletions aren't throttled. Deletions don't wait for the limiter: a run plans the deletions the limiter
+// has tokens for, at least one, and continues in a later reconcile.
+func (c *K8sClient) deletionBudget(cleanupConfig *cronschedulesv1.CleanupConfig) int {
+	if cleanupConfig.DeletesPerSecond <= 0 || cleanupConfig.DryRun {
+		return 0
+	}
+	if c.DeleteLimiter == nil {
+		c.DeleteLimiter = NewDeleteLimiter(cleanupConfig.DeletesPerSecond)
+	}
+	return max(1, int(c.DeleteLimiter.Tokens()))
+}
+
+// setPaging enables paged listing on the plan, resuming the run interrupted at resume when set. With
+// deletesPerSecond, paging applies with its defaults when it isn't configured, and pages are no larger than
+// maxDeletions, the deletions the rate allows in this reconcile.
+func (p *CleanupPlan) setPaging(cleanupConfig *cronschedulesv1.CleanupConfig, resume *cronschedulesv1.CleanupContinuation, maxDeletions int) {
+	paging := cleanupConfig.Paging
+	if paging == nil {
+		paging = &cronschedulesv1.CleanupPaging{}
+	}
+	p.pageSize = defaultPageSize
+	if paging.PageSize > 0 {
+		p.pageSize = int64(paging.PageSize)
+	}
+	p.maxPages = defaultMaxPagesPerRun
+	if paging.MaxPagesPerRun > 0 {
+		p.maxPages = int(paging.MaxPagesPerRun)
+	}
+	if maxDeletions > 0 {
+		p.maxDeletions = maxDeletions
+		p.pageSize = min(p.pageSize, int64(maxDeletions))
+	}
+	p.startedAt = p.now
+	if resume != nil {
+		p.resume = resume.DeepCopy()
+		p.startedAt = resume.StartedAt.Time
+		p.priorPlanned, p.priorMatched = int(resume.Planned), int(resume.Matched)
+		p.acknowledged = int(resume.Acknowledged)
+	}
+}
+
+// restart drops the continuation being resumed, so that a new run starts
+func (p *CleanupPlan) restart() {
+	p.resume = nil
+	p.startedAt = p.now
+	p.priorPlanned, p.priorMatched = 0, 0
+	p.acknowledged = 0
+}
+
+// paused reports whether the run has to stop listing for this reconcile: after maxPagesPerRun pages, or once
+// it has planned the deletions deletesPerSecond allows for now
+func (p *CleanupPlan) paused() bool {
+	return p.maxPages > 0 && p.pages >= p.maxPages || p.maxDeletions > 0 && len(p.Deletions) >= p.maxDeletions
+}
+
+// skipUntilResumed reports whether the listing of the resource type in the namespace comes before the
+// continuation of a resumed run. At the continuation, it hands its token over to planPages.
+func (p *CleanupPlan) skipUntilResumed(resourceType, namespace string) bool {
+	if p.resume == nil {
+		return false
+	}
+	if p.resume.ResourceType != resourceType || p.resume.Namespace != namespace {
+		return true
+	}
+	p.continueToken = p.resume.Continue
+	p.resume = nil
+	return false
+}
+
+// planPages plans the cleanup of a resource type in a namespace page by page, so that no more than a page of
+// resources is listed at once. Once the run is paused, it records where the run stopped in the plan's
+// Continuation, with the totals the deletion limits apply to.
+func (c *K8sClient) planPages(ctx context.Context, resourceType, namespace string, newList func() client.ObjectList,
+	listOpts []client.ListOption, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) error {
+	logger := log.FromContext(ctx)
+
+	token := plan.continueToken
+	plan.continueToken = ""
+	for {
+		if plan.paused() {
+			planned, matched := plan.Totals()
+			plan.Continuation = &cronschedulesv1.CleanupContinuation{
+				ResourceType: resourceType,
+				Namespace:    namespace,
+				Continue:     token,
+				StartedAt:    metav1.NewTime(plan.startedAt),
+				Planned:      int32(planned),
+				Matched:      int32(matched),
+				Acknowledged: int32(plan.acknowledged),
+			}
+			return nil
+		}
+
+		objList := newList()
+		opts := append(append([]client.ListOption{}, listOpts...), client.Limit(plan.pageSize), client.Continue(token))
+		if err := c.uncachedReader().List(ctx, objList, opts...); err != nil {
+			if apierrors.IsResourceExpired(err) && token != "" {
+				// The continuation outlived the API server's compaction window; list again from the start
+				logger.Info("Cleanup continuation expired, listing from the first page", "type", resourceType, "namespace", namespace)
+				token = ""
+				continue
+			}
+			return fmt.Errorf("failed to list %s in namespace %s: %w", resourceType, namespace, err)
+		}
+		plan.pages++
+		c.planItems(ctx, c.extractItemsFromList(objList), cleanupConfig, plan)
+
+		token = objList.GetContinue()
+		if token == "" {
+			return nil
+		}
+	}
+}
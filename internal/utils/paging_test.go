@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// newPagingTestClient returns a test client whose lists honor limit and continue, which the fake client
+// ignores. Continuation tokens are offsets; "expired" fails like a token past the compaction window.
+func newPagingTestClient(t *testing.T, objs ...client.Object) (*K8sClient, *[]int64) {
+	k8sClient := newOrphanTestClient(objs...)
+	var limits []int64
+	k8sClient.Client = interceptor.NewClient(k8sClient.Client.(client.WithWatch), interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+			limit, token := listOpts.Limit, listOpts.Continue
+			if token == "expired" {
+				return apierrors.NewResourceExpired("continuation token expired")
+			}
+			listOpts.Limit, listOpts.Continue = 0, ""
+			if err := c.List(ctx, list, listOpts); err != nil || limit == 0 {
+				return err
+			}
+			limits = append(limits, limit)
+
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			offset, _ := strconv.Atoi(token)
+			end := min(offset+int(limit), len(items))
+			if err := meta.SetList(list, items[offset:end]); err != nil {
+				t.Fatal(err)
+			}
+			if end < len(items) {
+				list.SetContinue(strconv.Itoa(end))
+			}
+			return nil
+		},
+	})
+	return k8sClient, &limits
+}
+
+func TestPagedCleanup(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	var objs []client.Object
+	for i := 1; i <= 5; i++ {
+		objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("cm-%d", i), Namespace: "default", Annotations: map[string]string{"cleanup-after": ""},
+		}})
+	}
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{"ConfigMap"},
+		Paging:        &cronschedulesv1.CleanupPaging{PageSize: 2, MaxPagesPerRun: 2},
+	}
+	startedAt := metav1.NewTime(time.Date(2025, 1, 20, 3, 0, 0, 0, time.UTC))
+
+	t.Run("stops after maxPagesPerRun and resumes", func(t *testing.T) {
+		k8sClient, limits := newPagingTestClient(t, objs...)
+		plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(plan.Deletions) != 4 || plan.Continuation == nil || plan.Continuation.Continue != "4" {
+			t.Fatalf("first run: %d deletions, continuation %+v; want 4 and a continuation at 4", len(plan.Deletions), plan.Continuation)
+		}
+		if len(*limits) != 2 || (*limits)[0] != 2 {
+			t.Errorf("list limits = %v, want 2 pages of 2", *limits)
+		}
+
+		resumed, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", plan.Continuation)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(resumed.Deletions) != 1 || resumed.Deletions[0].GetName() != "cm-5" || resumed.Continuation != nil {
+			t.Errorf("resumed run: %d deletions, continuation %+v; want cm-5 and no continuation", len(resumed.Deletions), resumed.Continuation)
+		}
+	})
+
+	t.Run("applies the deletion limits to the totals of the run", func(t *testing.T) {
+		k8sClient, _ := newPagingTestClient(t, objs...)
+		limited := cleanupConfig.DeepCopy()
+		limited.Paging.MaxPagesPerRun = 1
+		limited.MaxDeletionsPerRun = 3
+
+		plan, err := k8sClient.PlanCleanup(ctx, limited, "default", nil)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if err := plan.CheckLimits(limited); err != nil {
+			t.Fatalf("first part should be within the limits, got %v", err)
+		}
+		if plan.Continuation == nil || plan.Continuation.Planned != 2 || plan.Continuation.Matched != 2 {
+			t.Fatalf("continuation = %+v, want the totals of the first part", plan.Continuation)
+		}
+
+		resumed, err := k8sClient.PlanCleanup(ctx, limited, "default", plan.Continuation)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		var blocked *CleanupBlockedError
+		if err := resumed.CheckLimits(limited); !errors.As(err, &blocked) || blocked.Planned != 4 || blocked.Matched != 4 {
+			t.Errorf("CheckLimits() = %v, want the run blocked with 4 planned deletions", err)
+		}
+	})
+
+	t.Run("lists again from the start when the token expired", func(t *testing.T) {
+		k8sClient, _ := newPagingTestClient(t, objs...)
+		resume := &cronschedulesv1.CleanupContinuation{ResourceType: "ConfigMap", Namespace: "default", Continue: "expired", StartedAt: startedAt}
+		plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", resume)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(plan.Deletions) != 4 || plan.Deletions[0].GetName() != "cm-1" {
+			t.Errorf("deletions = %d, want the first 4 listed again", len(plan.Deletions))
+		}
+		if plan.Continuation == nil || !plan.Continuation.StartedAt.Equal(&startedAt) {
+			t.Errorf("continuation = %+v, want the start of the resumed run kept", plan.Continuation)
+		}
+	})
+
+	t.Run("starts over when the continuation no longer applies", func(t *testing.T) {
+		k8sClient, _ := newPagingTestClient(t, objs...)
+		resume := &cronschedulesv1.CleanupContinuation{ResourceType: "Secret", Namespace: "default", Continue: "2", StartedAt: startedAt}
+		plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", resume)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(plan.Deletions) != 4 || plan.Continuation.StartedAt.Equal(&startedAt) {
+			t.Errorf("deletions = %d, continuation %+v; want a new run from the first page", len(plan.Deletions), plan.Continuation)
+		}
+	})
+}
+
+func TestDeletesPerSecond(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	var objs []client.Object
+	for i := 1; i <= 3; i++ {
+		objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("cm-%d", i), Namespace: "default", Annotations: map[string]string{"cleanup-after": ""},
+		}})
+	}
+	k8sClient := newOrphanTestClient(objs...)
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey:    "cleanup-after",
+		ResourceTypes:    []string{"ConfigMap"},
+		DeletesPerSecond: 10,
+	}
+
+	result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+	if err != nil {
+		t.Fatalf("runCleanup() error = %v", err)
+	}
+	if result.Deleted != 3 {
+		t.Errorf("deleted = %d, want 3", result.Deleted)
+	}
+	if k8sClient.DeleteLimiter == nil {
+		t.Fatal("the run should keep its rate limiter for the next reconciles")
+	}
+	if tokens := k8sClient.DeleteLimiter.Tokens(); tokens > 8 {
+		t.Errorf("%.1f deletions left, want the 3 deletions taken from the second's worth of 10", tokens)
+	}
+
+	t.Run("plans the deletions the limiter has tokens for", func(t *testing.T) {
+		var many []client.Object
+		for i := 1; i <= 70; i++ {
+			many = append(many, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("cm-%02d", i), Namespace: "default", Annotations: map[string]string{"cleanup-after": ""},
+			}})
+		}
+		k8sClient, limits := newPagingTestClient(t, many...)
+		throttled := cleanupConfig.DeepCopy()
+		throttled.DeletesPerSecond = 20
+
+		plan, err := k8sClient.PlanCleanup(ctx, throttled, "default", nil)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(plan.Deletions) != 20 || plan.Continuation == nil || plan.Continuation.Continue != "20" {
+			t.Fatalf("%d deletions, continuation %+v; want 20 and a continuation at 20", len(plan.Deletions), plan.Continuation)
+		}
+		if len(*limits) != 1 || (*limits)[0] != 20 {
+			t.Errorf("list limits = %v, want a page of 20", *limits)
+		}
+
+		if result := k8sClient.ExecuteCleanupPlan(ctx, plan, throttled); result.Deleted != 20 {
+			t.Errorf("deleted = %d, want 20", result.Deleted)
+		}
+		if delay := DeletionDelay(k8sClient.DeleteLimiter); delay <= 0 {
+			t.Errorf("delay = %v, want the next part to wait for the rate limit", delay)
+		}
+
+		next, err := k8sClient.PlanCleanup(ctx, throttled, "default", plan.Continuation)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		if len(next.Deletions) == 0 || len(next.Deletions) >= 20 || next.Continuation == nil {
+			t.Errorf("%d deletions, continuation %+v; want the few deletions the rate allowed since and a continuation",
+				len(next.Deletions), next.Continuation)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const (
+	// QuarantineLabel marks resources in quarantine; removing it rescues the resource
+	QuarantineLabel = "cronjob-scale-down-operator/quarantined"
+	// QuarantineRescuedAnnotation holds the time a resource was rescued from quarantine; it is not
+	// quarantined again for the rescue period
+	QuarantineRescuedAnnotation = "cronjob-scale-down-operator/quarantine-rescued"
+
+	// quarantineUntilAnnotation holds the time a quarantined resource is deleted at
+	quarantineUntilAnnotation = "cronjob-scale-down-operator/quarantined-until"
+	// quarantineReplicasAnnotation holds the replicas of a quarantined workload
+	quarantineReplicasAnnotation = "cronjob-scale-down-operator/quarantine-replicas"
+	// quarantineSelectorAnnotation holds the selector of a quarantined Service
+	quarantineSelectorAnnotation = "cronjob-scale-down-operator/quarantine-selector"
+)
+
+type quarantineState int
+
+const (
+	notQuarantined quarantineState = iota
+	// quarantined resources are deleted once their deadline passes
+	quarantined
+	// released resources had their quarantine label removed and still have to be restored
+	released
+	// rescued resources were released and restored
+	rescued
+)
+
+// defaultRescuePeriod is how long rescued resources are not quarantined again when no rescue period is set
+const defaultRescuePeriod = 30 * Day
+
+// quarantineStateOf returns the quarantine state of obj and, while it is quarantined, its deadline, or once
+// rescued, its rescue time. The deadline is zero when it can't be read; such resources stay in quarantine
+// until rescued. The rescue time is zero when it can't be read; the rescue has expired then.
+func quarantineStateOf(obj client.Object) (quarantineState, time.Time) {
+	annotations := obj.GetAnnotations()
+	if value, ok := annotations[QuarantineRescuedAnnotation]; ok {
+		rescuedAt, _ := time.Parse(time.RFC3339, value)
+		return rescued, rescuedAt
+	}
+	until, ok := annotations[quarantineUntilAnnotation]
+	if !ok {
+		return notQuarantined, time.Time{}
+	}
+	if obj.GetLabels()[QuarantineLabel] != "true" {
+		return released, time.Time{}
+	}
+	deadline, _ := time.Parse(time.RFC3339, until)
+	return quarantined, deadline
+}
+
+// quarantineResource quarantines obj until the deadline: it is labeled, scaled to 0 if it has replicas,
+// and Services are detached from their pods. The replicas and selector are kept in annotations.
+func (c *K8sClient) quarantineResource(ctx context.Context, obj client.Object, cleanupConfig *cronschedulesv1.CleanupConfig, until time.Time) error {
+	logger := log.FromContext(ctx)
+
+	u, err := c.toUnstructured(obj)
+	if err != nil {
+		logger.Error(err, "Failed to quarantine resource", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return err
+	}
+	if cleanupConfig.DryRun {
+		logger.Info("DRY RUN: Would quarantine resource", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace(), "until", until)
+		return nil
+	}
+
+	original := u.DeepCopy()
+	labels := u.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[QuarantineLabel] = "true"
+	u.SetLabels(labels)
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[quarantineUntilAnnotation] = until.UTC().Format(time.RFC3339)
+	// A resource quarantined again after its rescue expired can be rescued again
+	delete(annotations, QuarantineRescuedAnnotation)
+
+	if replicas, found, err := unstructured.NestedInt64(u.Object, "spec", "replicas"); err == nil && found {
+		annotations[quarantineReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+		_ = unstructured.SetNestedField(u.Object, int64(0), "spec", "replicas")
+	}
+	if isService(u) {
+		if selector, found, _ := unstructured.NestedStringMap(u.Object, "spec", "selector"); found && len(selector) > 0 {
+			data, _ := json.Marshal(selector)
+			annotations[quarantineSelectorAnnotation] = string(data)
+			unstructured.RemoveNestedField(u.Object, "spec", "selector")
+		}
+	}
+	u.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, u, client.MergeFrom(original)); err != nil {
+		logger.Error(err, "Failed to quarantine resource", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace())
+		return err
+	}
+	logger.Info("Quarantined resource", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace(), "until", until)
+	return nil
+}
+
+// rescueResource restores a resource whose quarantine label was removed and marks it as rescued
+func (c *K8sClient) rescueResource(ctx context.Context, obj client.Object, cleanupConfig *cronschedulesv1.CleanupConfig) {
+	logger := log.FromContext(ctx)
+
+	u, err := c.toUnstructured(obj)
+	if err != nil {
+		logger.Error(err, "Failed to rescue resource", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return
+	}
+	if cleanupConfig.DryRun {
+		logger.Info("DRY RUN: Would rescue resource from quarantine", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace())
+		return
+	}
+
+	original := u.DeepCopy()
+	liftQuarantine(u)
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[QuarantineRescuedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	u.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, u, client.MergeFrom(original)); err != nil {
+		logger.Error(err, "Failed to rescue resource", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace())
+		return
+	}
+	logger.Info("Rescued resource from quarantine", "type", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace())
+}
+
+// liftQuarantine restores the replicas and selector of a quarantined resource and removes its quarantine
+// label and annotations
+func liftQuarantine(u *unstructured.Unstructured) {
+	annotations := u.GetAnnotations()
+
+	if value, ok := annotations[quarantineReplicasAnnotation]; ok {
+		if replicas, err := strconv.ParseInt(value, 10, 64); err == nil {
+			_ = unstructured.SetNestedField(u.Object, replicas, "spec", "replicas")
+		}
+	}
+	if value, ok := annotations[quarantineSelectorAnnotation]; ok && isService(u) {
+		selector := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &selector); err == nil {
+			_ = unstructured.SetNestedStringMap(u.Object, selector, "spec", "selector")
+		}
+	}
+
+	for _, key := range []string{quarantineUntilAnnotation, quarantineReplicasAnnotation, quarantineSelectorAnnotation} {
+		delete(annotations, key)
+	}
+	u.SetAnnotations(annotations)
+
+	labels := u.GetLabels()
+	delete(labels, QuarantineLabel)
+	u.SetLabels(labels)
+}
+
+// isService reports whether u is a core Service
+func isService(u *unstructured.Unstructured) bool {
+	gvk := u.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Service"
+}
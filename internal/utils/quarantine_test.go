@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestQuarantineCleanup(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	expired := map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"}
+
+	k8sClient := newOrphanTestClient(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "preview", Namespace: "default", Annotations: expired},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "preview", Namespace: "default", Annotations: expired},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "preview"}},
+		},
+	)
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey:    "cleanup-after",
+		ResourceTypes:    []string{"Deployment", "Service"},
+		QuarantinePeriod: "1d",
+	}
+	deploymentKey := client.ObjectKey{Namespace: "default", Name: "preview"}
+
+	run := func() (*CleanupPlan, CleanupResult) {
+		t.Helper()
+		plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		return plan, k8sClient.ExecuteCleanupPlan(ctx, plan, cleanupConfig)
+	}
+
+	// First match: both resources are quarantined, nothing is deleted
+	plan, result := run()
+	if result.Quarantined != 2 || result.Deleted != 0 || len(result.QuarantinedResources) != 2 {
+		t.Fatalf("first run = %+v, want 2 quarantined", result)
+	}
+	var blocked *CleanupBlockedError
+	if err := plan.CheckLimits(&cronschedulesv1.CleanupConfig{MaxDeletionsPerRun: 1}); !errors.As(err, &blocked) {
+		t.Errorf("quarantines should count against the deletion limits, got %v", err)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := k8sClient.Get(ctx, deploymentKey, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Labels[QuarantineLabel] != "true" || *deployment.Spec.Replicas != 0 || deployment.Annotations[quarantineReplicasAnnotation] != "3" {
+		t.Errorf("deployment not quarantined: labels %v, replicas %d, annotations %v", deployment.Labels, *deployment.Spec.Replicas, deployment.Annotations)
+	}
+	service := &corev1.Service{}
+	if err := k8sClient.Get(ctx, deploymentKey, service); err != nil {
+		t.Fatal(err)
+	}
+	if len(service.Spec.Selector) != 0 || service.Annotations[quarantineSelectorAnnotation] == "" {
+		t.Errorf("service not detached: selector %v", service.Spec.Selector)
+	}
+
+	// Before the deadline the resources stay in quarantine
+	_, result = run()
+	if result.Quarantined != 0 || result.Deleted != 0 || len(result.QuarantinedResources) != 2 {
+		t.Fatalf("second run = %+v, want 2 resources kept in quarantine", result)
+	}
+
+	// Past the deadline the deployment is deleted
+	deployment.Annotations[quarantineUntilAnnotation] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if err := k8sClient.Update(ctx, deployment); err != nil {
+		t.Fatal(err)
+	}
+	// Removing the label rescues the service
+	delete(service.Labels, QuarantineLabel)
+	if err := k8sClient.Update(ctx, service); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, result = run()
+	if result.Deleted != 1 || len(plan.Rescues) != 1 {
+		t.Fatalf("third run = %+v with %d rescues, want 1 deletion and 1 rescue", result, len(plan.Rescues))
+	}
+	if err := plan.CheckLimits(&cronschedulesv1.CleanupConfig{MaxDeletionsPerRun: 0, MaxDeletionPercent: 1}); err != nil {
+		t.Errorf("deletions at the end of quarantine should not count again, got %v", err)
+	}
+	if err := k8sClient.Get(ctx, deploymentKey, &appsv1.Deployment{}); !apierrors.IsNotFound(err) {
+		t.Errorf("deployment should be deleted, got %v", err)
+	}
+	if err := k8sClient.Get(ctx, deploymentKey, service); err != nil {
+		t.Fatal(err)
+	}
+	if service.Spec.Selector["app"] != "preview" || service.Annotations[QuarantineRescuedAnnotation] == "" {
+		t.Errorf("service not restored: selector %v, annotations %v", service.Spec.Selector, service.Annotations)
+	}
+
+	// Rescued resources are not quarantined again during the rescue period
+	plan, result = run()
+	if result.Quarantined != 0 || len(plan.Skipped) != 1 || plan.Skipped[0].Reason != "rescued from quarantine" {
+		t.Errorf("fourth run = %+v, skipped %v", result, plan.Skipped)
+	}
+
+	// Once the rescue has expired, the service is quarantined again
+	service.Annotations[QuarantineRescuedAnnotation] = time.Now().Add(-31 * Day).UTC().Format(time.RFC3339)
+	if err := k8sClient.Update(ctx, service); err != nil {
+		t.Fatal(err)
+	}
+	if _, result = run(); result.Quarantined != 1 {
+		t.Fatalf("fifth run = %+v, want the service quarantined again", result)
+	}
+	if err := k8sClient.Get(ctx, deploymentKey, service); err != nil {
+		t.Fatal(err)
+	}
+	if service.Labels[QuarantineLabel] != "true" || service.Annotations[QuarantineRescuedAnnotation] != "" {
+		t.Errorf("service not quarantined again: labels %v, annotations %v", service.Labels, service.Annotations)
+	}
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// cleanupReason returns why a resource due for cleanup is deleted: its cleanup annotation expired, the
+// annotation is empty and asks for immediate cleanup, or the resource is an orphan past its max age
+func cleanupReason(obj client.Object, annotationKey string) string {
+	value, ok := obj.GetAnnotations()[annotationKey]
+	switch {
+	case !ok:
+		return cronschedulesv1.CleanupReasonOrphanAge
+	case value == "":
+		return cronschedulesv1.CleanupReasonImmediate
+	default:
+		return cronschedulesv1.CleanupReasonAnnotation
+	}
+}
+
+// record adds what the run did to a resource to the report, up to maxSkippedResources
+func (r *CleanupResult) record(kind string, obj client.Object, action, reason, group string, err error) {
+	if len(r.Records) >= maxSkippedResources {
+		r.Truncated++
+		return
+	}
+	record := cronschedulesv1.CleanupRecord{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Action:    action,
+		Reason:    reason,
+		Group:     group,
+	}
+	if err != nil {
+		record.Message = err.Error()
+	}
+	r.Records = append(r.Records, record)
+}
+
+// Report returns the report of the run for the cleanup history
+func (r CleanupResult) Report(now time.Time, dryRun bool) cronschedulesv1.CleanupRun {
+	return cronschedulesv1.CleanupRun{
+		Time:        metav1.NewTime(now),
+		DryRun:      dryRun,
+		Deleted:     r.Deleted,
+		Quarantined: r.Quarantined,
+		Failed:      r.Failed,
+		Resources:   r.Records,
+		Truncated:   r.Truncated,
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCleanupReport(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+	configMap := func(name string, annotations, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", CreationTimestamp: old, Annotations: annotations, Labels: labels,
+		}}
+	}
+	k8sClient := newOrphanTestClient(
+		configMap("expired", map[string]string{"cleanup-after": "1h"}, nil),
+		configMap("immediate", map[string]string{"cleanup-after": ""}, nil),
+		configMap("orphan", nil, nil),
+		configMap("stuck", map[string]string{"cleanup-after": "1h"}, nil),
+		configMap("kept", map[string]string{"cleanup-after": "1h"}, map[string]string{"cronjob-scale-down-operator/protected": "true"}),
+	)
+	k8sClient.Client = interceptor.NewClient(k8sClient.Client.(client.WithWatch), interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if obj.GetName() == "stuck" {
+				return errors.New("admission webhook denied the request")
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey:          "cleanup-after",
+		ResourceTypes:          []string{"ConfigMap"},
+		CleanupOrphanResources: true,
+		OrphanResourceMaxAge:   "1d",
+	}
+	result, err := runCleanup(ctx, k8sClient, cleanupConfig, "default")
+	if err != nil {
+		t.Fatalf("runCleanup() error = %v", err)
+	}
+
+	want := map[string]cronschedulesv1.CleanupRecord{
+		"expired":   {Action: cronschedulesv1.CleanupActionDeleted, Reason: cronschedulesv1.CleanupReasonAnnotation},
+		"immediate": {Action: cronschedulesv1.CleanupActionDeleted, Reason: cronschedulesv1.CleanupReasonImmediate},
+		"orphan":    {Action: cronschedulesv1.CleanupActionDeleted, Reason: cronschedulesv1.CleanupReasonOrphanAge},
+		"stuck":     {Action: cronschedulesv1.CleanupActionFailed, Reason: cronschedulesv1.CleanupReasonAnnotation, Message: "admission webhook denied the request"},
+		"kept":      {Action: cronschedulesv1.CleanupActionSkipped, Reason: "marked with cronjob-scale-down-operator/protected"},
+	}
+	if len(result.Records) != len(want) {
+		t.Fatalf("records = %+v, want %d", result.Records, len(want))
+	}
+	for _, record := range result.Records {
+		expected := want[record.Name]
+		if record.Kind != "ConfigMap" || record.Action != expected.Action || record.Reason != expected.Reason || record.Message != expected.Message {
+			t.Errorf("record %s = %+v, want %+v", record.Name, record, expected)
+		}
+	}
+
+	run := result.Report(time.Now(), false)
+	if run.Deleted != 3 || run.Failed != 1 || run.Truncated != 0 {
+		t.Errorf("report = %+v, want 3 deleted and 1 failed", run)
+	}
+
+	// Dry runs report what they would delete
+	cleanupConfig.DryRun = true
+	result, err = runCleanup(ctx, k8sClient, cleanupConfig, "default")
+	if err != nil {
+		t.Fatalf("runCleanup() error = %v", err)
+	}
+	for _, record := range result.Records {
+		if record.Name == "stuck" && record.Action != cronschedulesv1.CleanupActionWouldDelete {
+			t.Errorf("dry run record = %+v, want %s", record, cronschedulesv1.CleanupActionWouldDelete)
+		}
+	}
+}
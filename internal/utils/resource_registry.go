@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// builtinResourceType describes a kind that cleanup lists through typed, cached clients
+type builtinResourceType struct {
+	newList    func() client.ObjectList
+	namespaced bool
+}
+
+// resourceRegistry holds the built-in cleanup kinds. It is the single source for cleanup
+// validation and listing; every entry must carry the RBAC marker granting the operator
+// list and delete on its resource (enforced by TestResourceRegistryRBACMarkers), create so
+// that archived objects of the kind can be restored, and patch so that they can be quarantined.
+// RBAC kinds are never restored nor patched, so the operator can't be used to change bindings.
+var resourceRegistry = map[string]builtinResourceType{
+	//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+	"Deployment": {newList: func() client.ObjectList { return &appsv1.DeploymentList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+	"StatefulSet": {newList: func() client.ObjectList { return &appsv1.StatefulSetList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;patch;delete
+	"Service": {newList: func() client.ObjectList { return &corev1.ServiceList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;patch;delete
+	"ConfigMap": {newList: func() client.ObjectList { return &corev1.ConfigMapList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;patch;delete
+	"Secret": {newList: func() client.ObjectList { return &corev1.SecretList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;patch;delete
+	"Pod": {newList: func() client.ObjectList { return &corev1.PodList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;patch;delete
+	"Job": {newList: func() client.ObjectList { return &batchv1.JobList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;patch;delete
+	"Ingress": {newList: func() client.ObjectList { return &networkingv1.IngressList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;patch;delete
+	"HorizontalPodAutoscaler": {newList: func() client.ObjectList { return &autoscalingv2.HorizontalPodAutoscalerList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;patch;delete
+	"PodDisruptionBudget": {newList: func() client.ObjectList { return &policyv1.PodDisruptionBudgetList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;delete
+	"Role": {newList: func() client.ObjectList { return &rbacv1.RoleList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;delete
+	"RoleBinding": {newList: func() client.ObjectList { return &rbacv1.RoleBindingList{} }, namespaced: true},
+	//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;delete
+	"ClusterRole": {newList: func() client.ObjectList { return &rbacv1.ClusterRoleList{} }, namespaced: false},
+	//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;delete
+	"ClusterRoleBinding": {newList: func() client.ObjectList { return &rbacv1.ClusterRoleBindingList{} }, namespaced: false},
+}
+
+// BuiltinResourceTypes returns the names of the built-in cleanup kinds, sorted
+func BuiltinResourceTypes() []string {
+	names := make([]string, 0, len(resourceRegistry))
+	for name := range resourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isBuiltinResourceType reports whether the resource type is one of the kinds in the registry
+func isBuiltinResourceType(resourceType string) bool {
+	_, ok := resourceRegistry[resourceType]
+	return ok
+}
+
+// createResourceList creates the typed list object for a built-in resource type
+func (c *K8sClient) createResourceList(resourceType string) (client.ObjectList, error) {
+	entry, ok := resourceRegistry[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+	return entry.newList(), nil
+}
+
+// extractItemsFromList extracts the items of a typed list
+func (c *K8sClient) extractItemsFromList(objList client.ObjectList) []client.Object {
+	objs, err := meta.ExtractList(objList)
+	if err != nil {
+		return nil
+	}
+
+	items := make([]client.Object, 0, len(objs))
+	for _, obj := range objs {
+		if item, ok := obj.(client.Object); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
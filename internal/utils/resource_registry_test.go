@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// TestResourceRegistryRBACMarkers makes sure every registry entry is preceded by an RBAC marker
+// for its resource granting list and delete, so the generated ClusterRole covers it
+func TestResourceRegistryRBACMarkers(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "resource_registry.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse resource_registry.go: %v", err)
+	}
+
+	markers := map[int]string{}
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, "//+kubebuilder:rbac:") {
+				markers[fset.Position(comment.Pos()).Line] = comment.Text
+			}
+		}
+	}
+
+	found := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		lit, ok := kv.Key.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		kind, _ := strconv.Unquote(lit.Value)
+		found[kind] = true
+
+		marker := markers[fset.Position(kv.Pos()).Line-1]
+		if marker == "" {
+			t.Errorf("registry entry %s has no RBAC marker on the preceding line", kind)
+			return false
+		}
+
+		list, err := (&K8sClient{}).createResourceList(kind)
+		if err != nil {
+			t.Errorf("failed to create list for %s: %v", kind, err)
+			return false
+		}
+		resource := strings.ToLower(strings.TrimSuffix(reflect.TypeOf(list).Elem().Name(), "List"))
+		if strings.HasSuffix(resource, "s") {
+			resource += "es"
+		} else {
+			resource += "s"
+		}
+		if !strings.Contains(marker, "resources="+resource+",") {
+			t.Errorf("RBAC marker for %s doesn't grant %s: %s", kind, resource, marker)
+		}
+		for _, verb := range []string{"list", "delete"} {
+			if !strings.Contains(marker, verb) {
+				t.Errorf("RBAC marker for %s doesn't grant %s: %s", kind, verb, marker)
+			}
+		}
+		return false
+	})
+
+	for _, kind := range BuiltinResourceTypes() {
+		if !found[kind] {
+			t.Errorf("registry entry %s not found in source", kind)
+		}
+	}
+}
+
+func TestMatchesStateFilters(t *testing.T) {
+	pod := func(phase corev1.PodPhase, reason string) client.Object {
+		return &corev1.Pod{Status: corev1.PodStatus{Phase: phase, Reason: reason}}
+	}
+	job := func(conditionType batchv1.JobConditionType) client.Object {
+		j := &batchv1.Job{}
+		if conditionType != "" {
+			j.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		}
+		return j
+	}
+
+	tests := []struct {
+		name   string
+		obj    client.Object
+		config cronschedulesv1.CleanupConfig
+		want   bool
+	}{
+		{name: "pod without filter", obj: pod(corev1.PodRunning, ""), want: true},
+		{name: "succeeded pod", obj: pod(corev1.PodSucceeded, ""), config: cronschedulesv1.CleanupConfig{PodPhases: []string{"Succeeded"}}, want: true},
+		{name: "running pod filtered out", obj: pod(corev1.PodRunning, ""), config: cronschedulesv1.CleanupConfig{PodPhases: []string{"Failed", "Succeeded"}}, want: false},
+		{name: "evicted pod", obj: pod(corev1.PodFailed, "Evicted"), config: cronschedulesv1.CleanupConfig{PodPhases: []string{"Evicted"}}, want: true},
+		{name: "failed pod is not evicted", obj: pod(corev1.PodFailed, "Error"), config: cronschedulesv1.CleanupConfig{PodPhases: []string{"Evicted"}}, want: false},
+		{name: "complete job", obj: job(batchv1.JobComplete), config: cronschedulesv1.CleanupConfig{JobConditions: []string{"Complete"}}, want: true},
+		{name: "running job filtered out", obj: job(""), config: cronschedulesv1.CleanupConfig{JobConditions: []string{"Complete", "Failed"}}, want: false},
+		{name: "failed job filtered out", obj: job(batchv1.JobFailed), config: cronschedulesv1.CleanupConfig{JobConditions: []string{"Complete"}}, want: false},
+		{name: "other kinds ignore filters", obj: &corev1.ConfigMap{}, config: cronschedulesv1.CleanupConfig{PodPhases: []string{"Failed"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesStateFilters(tt.obj, &tt.config); got != tt.want {
+				t.Errorf("matchesStateFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
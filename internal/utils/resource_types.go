@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// ParseResourceType parses a cleanup resource type that isn't one of the built-in kinds.
+// Supported forms are "group/version/kind" ("version/kind" for the core group) and "kind.group";
+// a bare kind refers to the core group. The version is empty when it should be resolved by the RESTMapper.
+func ParseResourceType(resourceType string) (schema.GroupVersionKind, error) {
+	if resourceType == "" || strings.TrimSpace(resourceType) != resourceType {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid resource type %q", resourceType)
+	}
+
+	if strings.Contains(resourceType, "/") {
+		parts := strings.Split(resourceType, "/")
+		var gvk schema.GroupVersionKind
+		switch len(parts) {
+		case 2:
+			gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+		case 3:
+			gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+		default:
+			return schema.GroupVersionKind{}, fmt.Errorf("invalid resource type %q: expected group/version/kind", resourceType)
+		}
+		if gvk.Version == "" || gvk.Kind == "" {
+			return schema.GroupVersionKind{}, fmt.Errorf("invalid resource type %q: expected group/version/kind", resourceType)
+		}
+		return gvk, nil
+	}
+
+	kind, group, _ := strings.Cut(resourceType, ".")
+	if kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid resource type %q: expected kind.group", resourceType)
+	}
+	return schema.GroupVersionKind{Group: group, Kind: kind}, nil
+}
+
+// IsSupportedResourceType reports whether the resource type is a built-in kind, HelmRelease, or can be parsed
+// by ParseResourceType
+func IsSupportedResourceType(resourceType string) bool {
+	if isBuiltinResourceType(resourceType) || resourceType == HelmReleaseResourceType {
+		return true
+	}
+	_, err := ParseResourceType(resourceType)
+	return err == nil
+}
+
+// ResolveResourceType maps a cleanup resource type that isn't one of the built-in kinds to its REST mapping
+func (c *K8sClient) ResolveResourceType(resourceType string) (*meta.RESTMapping, error) {
+	gvk, err := ParseResourceType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	if gvk.Version != "" {
+		versions = append(versions, gvk.Version)
+	}
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), versions...)
+	if err != nil {
+		return nil, fmt.Errorf("resource type %q is not served by the cluster: %w", resourceType, err)
+	}
+	return mapping, nil
+}
+
+// CleanupVerbs returns the verbs the operator needs on the resource types of the cleanup config
+func CleanupVerbs(cleanupConfig *cronschedulesv1.CleanupConfig) []string {
+	verbs := []string{"list", "delete"}
+	if cleanupConfig.QuarantinePeriod != "" || cleanupConfig.WarnBefore != "" {
+		verbs = append(verbs, "patch")
+	}
+	return verbs
+}
+
+// UnpatchableResourceType returns the first of the resource types the operator is not allowed to patch, or an
+// empty string. The operator has no patch permission on RBAC objects, so that it can't be used to change them.
+func UnpatchableResourceType(resourceTypes []string) string {
+	for _, resourceType := range resourceTypes {
+		if groupKind, err := resourceTypeGroupKind(resourceType); err == nil && groupKind.Group == rbacv1.GroupName {
+			return resourceType
+		}
+	}
+	return ""
+}
+
+// ValidateCleanupResourceTypes checks that every resource type to clean up exists in the cluster and
+// that the operator has the verbs on it in the given namespaces
+func (c *K8sClient) ValidateCleanupResourceTypes(ctx context.Context, resourceTypes, namespaces, verbs []string) error {
+	for _, resourceType := range resourceTypes {
+		if isBuiltinResourceType(resourceType) || resourceType == HelmReleaseResourceType {
+			continue
+		}
+
+		mapping, err := c.ResolveResourceType(resourceType)
+		if err != nil {
+			return err
+		}
+
+		scopes := namespaces
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			scopes = []string{""}
+		}
+		for _, namespace := range scopes {
+			for _, verb := range verbs {
+				allowed, err := c.CanI(ctx, verb, mapping.Resource, namespace)
+				if err != nil {
+					return fmt.Errorf("failed to check %s permission on %s: %w", verb, resourceType, err)
+				}
+				if !allowed {
+					return fmt.Errorf("operator is not allowed to %s %s in namespace %q", verb, mapping.Resource.String(), namespace)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CanI reports whether the operator may perform the verb on the resource in the namespace ("" for all namespaces)
+func (c *K8sClient) CanI(ctx context.Context, verb string, resource schema.GroupVersionResource, namespace string) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     resource.Group,
+				Version:   resource.Version,
+				Resource:  resource.Resource,
+			},
+		},
+	}
+	if err := c.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// resourceListFactory returns how to create the list object of a resource type, typed for the built-in kinds
+// and unstructured for the kinds resolved through the RESTMapper, and whether the resource type is namespaced
+func (c *K8sClient) resourceListFactory(resourceType string) (func() client.ObjectList, bool, error) {
+	if entry, ok := resourceRegistry[resourceType]; ok {
+		return entry.newList, entry.namespaced, nil
+	}
+
+	mapping, err := c.ResolveResourceType(resourceType)
+	if err != nil {
+		return nil, false, err
+	}
+	listKind := mapping.GroupVersionKind.GroupVersion().WithKind(mapping.GroupVersionKind.Kind + "List")
+	newList := func() client.ObjectList {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(listKind)
+		return list
+	}
+	return newList, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
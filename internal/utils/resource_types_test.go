@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+func TestParseResourceType(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		want         schema.GroupVersionKind
+		wantErr      bool
+	}{
+		{resourceType: "cert-manager.io/v1/Certificate", want: certificateGVK},
+		{resourceType: "v1/PersistentVolumeClaim", want: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}},
+		{resourceType: "Application.argoproj.io", want: schema.GroupVersionKind{Group: "argoproj.io", Kind: "Application"}},
+		{resourceType: "Ingress.networking.k8s.io", want: schema.GroupVersionKind{Group: "networking.k8s.io", Kind: "Ingress"}},
+		{resourceType: "PersistentVolumeClaim", want: schema.GroupVersionKind{Kind: "PersistentVolumeClaim"}},
+		{resourceType: "a/b/c/d", wantErr: true},
+		{resourceType: "cert-manager.io//Certificate", wantErr: true},
+		{resourceType: ".argoproj.io", wantErr: true},
+		{resourceType: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			got, err := ParseResourceType(tt.resourceType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourceType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseResourceType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newUnstructuredTestClient(allowed bool, objs ...client.Object) *K8sClient {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{certificateGVK.GroupVersion()})
+	mapper.Add(certificateGVK, meta.RESTScopeNamespace)
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = cronschedulesv1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRESTMapper(mapper).
+		WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+					review.Status.Allowed = allowed
+					return nil
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	return &K8sClient{Client: fakeClient}
+}
+
+func TestValidateCleanupResourceTypes(t *testing.T) {
+	ctx := context.Background()
+
+	if err := newUnstructuredTestClient(true).ValidateCleanupResourceTypes(ctx, []string{"ConfigMap", "Certificate.cert-manager.io"}, []string{"default"}, []string{"list", "delete"}); err != nil {
+		t.Errorf("expected resource types to be valid, got %v", err)
+	}
+	if err := newUnstructuredTestClient(true).ValidateCleanupResourceTypes(ctx, []string{"Application.argoproj.io"}, []string{"default"}, []string{"list", "delete"}); err == nil {
+		t.Errorf("expected unknown kind to fail validation")
+	}
+	if err := newUnstructuredTestClient(false).ValidateCleanupResourceTypes(ctx, []string{"cert-manager.io/v1/Certificate"}, []string{"default"}, []string{"list", "delete"}); err == nil {
+		t.Errorf("expected missing permissions to fail validation")
+	}
+
+	// Quarantine needs patch too
+	patchDenied := newUnstructuredTestClient(true)
+	patchDenied.Client = interceptor.NewClient(patchDenied.Client.(client.WithWatch), interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if err := c.Create(ctx, obj, opts...); err != nil {
+				return err
+			}
+			if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok && review.Spec.ResourceAttributes.Verb == "patch" {
+				review.Status.Allowed = false
+			}
+			return nil
+		},
+	})
+	quarantine := &cronschedulesv1.CleanupConfig{QuarantinePeriod: "1d"}
+	if err := patchDenied.ValidateCleanupResourceTypes(ctx, []string{"Certificate.cert-manager.io"}, []string{"default"}, CleanupVerbs(quarantine)); err == nil {
+		t.Errorf("expected missing patch permission to fail validation with quarantine")
+	}
+	warnBefore := &cronschedulesv1.CleanupConfig{WarnBefore: "1d"}
+	if err := patchDenied.ValidateCleanupResourceTypes(ctx, []string{"Certificate.cert-manager.io"}, []string{"default"}, CleanupVerbs(warnBefore)); err == nil {
+		t.Errorf("expected missing patch permission to fail validation with warnBefore")
+	}
+	if UnpatchableResourceType([]string{"ConfigMap", "ClusterRoleBinding"}) != "ClusterRoleBinding" || UnpatchableResourceType([]string{"Deployment"}) != "" {
+		t.Errorf("expected RBAC kinds, and only them, to be reported as unpatchable")
+	}
+}
+
+func TestCleanupUnstructuredResources(t *testing.T) {
+	certificate := func(name string, annotations map[string]string) client.Object {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(certificateGVK)
+		obj.SetName(name)
+		obj.SetNamespace("default")
+		obj.SetAnnotations(annotations)
+		obj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+		return obj
+	}
+
+	k8sClient := newUnstructuredTestClient(true,
+		certificate("stale", map[string]string{"cleanup": ""}),
+		certificate("kept", nil),
+	)
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	result, err := runCleanup(ctx, k8sClient, &cronschedulesv1.CleanupConfig{
+		ResourceTypes: []string{"Certificate.cert-manager.io"},
+		AnnotationKey: "cleanup",
+	}, "default")
+	if err != nil {
+		t.Fatalf("runCleanup returned error: %v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("expected 1 deleted certificate, got %d", result.Deleted)
+	}
+
+	remaining := &unstructured.UnstructuredList{}
+	remaining.SetGroupVersionKind(certificateGVK.GroupVersion().WithKind("CertificateList"))
+	if err := k8sClient.List(ctx, remaining, client.InNamespace("default")); err != nil {
+		t.Fatalf("failed to list certificates: %v", err)
+	}
+	if len(remaining.Items) != 1 || remaining.Items[0].GetName() != "kept" {
+		t.Errorf("expected only the unannotated certificate to remain, got %v", remaining.Items)
+	}
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+const (
+	annotationKeyOriginalResources = "cronjob-scale-down-operator/original-resources"
+	// rightsizeWildcard matches every container not listed by name in a rightsize profile
+	rightsizeWildcard = "*"
+)
+
+// RightsizeTargetResource applies the profile to the container resources of the target's pod template.
+// The original resources are saved in an annotation, unless already saved, so they can be restored at scale up.
+func (c *K8sClient) RightsizeTargetResource(ctx context.Context, targetRef TargetObject, profile *cronschedulesv1.RightsizeProfile) error {
+	logger := log.FromContext(ctx)
+
+	if profile == nil {
+		return fmt.Errorf("rightsize profile is nil")
+	}
+
+	obj, template, err := c.getTargetPodTemplate(ctx, targetRef)
+	if err != nil {
+		logger.Error(err, "Failed to get target resource for rightsizing", "name", targetRef.Name)
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := annotations[annotationKeyOriginalResources]; !ok {
+		original := make(map[string]corev1.ResourceRequirements, len(template.Spec.Containers))
+		for _, container := range template.Spec.Containers {
+			original[container.Name] = container.Resources
+		}
+		value, err := json.Marshal(original)
+		if err != nil {
+			return fmt.Errorf("failed to encode original resources: %w", err)
+		}
+		annotations[annotationKeyOriginalResources] = string(value)
+		obj.SetAnnotations(annotations)
+	}
+
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		resources := profileResources(profile, container.Name)
+		if resources == nil {
+			continue
+		}
+		container.Resources.Requests = mergeResourceList(container.Resources.Requests, resources.Requests)
+		container.Resources.Limits = mergeResourceList(container.Resources.Limits, resources.Limits)
+	}
+
+	if err := c.Update(ctx, obj); err != nil {
+		logger.Error(err, "Failed to rightsize target resource", "name", targetRef.Name)
+		return err
+	}
+
+	logger.Info("Target resource rightsized successfully", "kind", targetRef.Kind, "name", targetRef.Name)
+	return nil
+}
+
+// RestoreTargetResources restores the container resources saved by RightsizeTargetResource
+func (c *K8sClient) RestoreTargetResources(ctx context.Context, targetRef TargetObject) error {
+	logger := log.FromContext(ctx)
+
+	obj, template, err := c.getTargetPodTemplate(ctx, targetRef)
+	if err != nil {
+		logger.Error(err, "Failed to get target resource for restoring resources", "name", targetRef.Name)
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	value, ok := annotations[annotationKeyOriginalResources]
+	if !ok {
+		logger.Info("No original resources annotation found, nothing to restore", "name", targetRef.Name)
+		return nil
+	}
+
+	original := map[string]corev1.ResourceRequirements{}
+	if err := json.Unmarshal([]byte(value), &original); err != nil {
+		logger.Error(err, "Invalid original resources annotation value", "value", value)
+		return err
+	}
+
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if resources, ok := original[container.Name]; ok {
+			container.Resources = resources
+		}
+	}
+	delete(annotations, annotationKeyOriginalResources)
+	obj.SetAnnotations(annotations)
+
+	if err := c.Update(ctx, obj); err != nil {
+		logger.Error(err, "Failed to restore target resources", "name", targetRef.Name)
+		return err
+	}
+
+	logger.Info("Successfully restored target resources", "kind", targetRef.Kind, "name", targetRef.Name)
+	return nil
+}
+
+// getTargetPodTemplate returns the target resource and a pointer to its pod template
+func (c *K8sClient) getTargetPodTemplate(ctx context.Context, targetRef TargetObject) (client.Object, *corev1.PodTemplateSpec, error) {
+	key := client.ObjectKey{Name: targetRef.Name, Namespace: targetRef.Namespace}
+
+	switch targetRef.Kind {
+	case DeploymentKind:
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, deployment); err != nil {
+			return nil, nil, err
+		}
+		return deployment, &deployment.Spec.Template, nil
+	case StatefulSetKind:
+		statefulset := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, key, statefulset); err != nil {
+			return nil, nil, err
+		}
+		return statefulset, &statefulset.Spec.Template, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported target resource kind: %s", targetRef.Kind)
+	}
+}
+
+// profileResources returns the profile entry for a container, falling back to the wildcard entry
+func profileResources(profile *cronschedulesv1.RightsizeProfile, name string) *cronschedulesv1.ContainerResources {
+	var wildcard *cronschedulesv1.ContainerResources
+	for i := range profile.Containers {
+		switch profile.Containers[i].Name {
+		case name:
+			return &profile.Containers[i]
+		case rightsizeWildcard:
+			wildcard = &profile.Containers[i]
+		}
+	}
+	return wildcard
+}
+
+func mergeResourceList(current, overrides corev1.ResourceList) corev1.ResourceList {
+	if len(overrides) == 0 {
+		return current
+	}
+	merged := current.DeepCopy()
+	if merged == nil {
+		merged = corev1.ResourceList{}
+	}
+	for name, quantity := range overrides {
+		merged[name] = quantity.DeepCopy()
+	}
+	return merged
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestRightsizeAndRestoreTargetResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("2"),
+									corev1.ResourceMemory: resource.MustParse("4Gi"),
+								},
+							},
+						},
+						{Name: "sidecar"},
+					},
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build()
+	k8sClient := &K8sClient{Client: fakeClient}
+	ctx := log.IntoContext(context.Background(), log.Log)
+	target := TargetObject{TargetRef: cronschedulesv1.TargetRef{Name: "api", Namespace: "default", Kind: DeploymentKind}}
+	key := client.ObjectKey{Name: "api", Namespace: "default"}
+
+	profile := &cronschedulesv1.RightsizeProfile{
+		Containers: []cronschedulesv1.ContainerResources{
+			{Name: "app", Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+			{Name: "*", Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}},
+		},
+	}
+
+	if err := k8sClient.RightsizeTargetResource(ctx, target, profile); err != nil {
+		t.Fatalf("RightsizeTargetResource returned error: %v", err)
+	}
+	// A second run must keep the originals saved by the first
+	if err := k8sClient.RightsizeTargetResource(ctx, target, profile); err != nil {
+		t.Fatalf("RightsizeTargetResource returned error: %v", err)
+	}
+
+	rightsized := &appsv1.Deployment{}
+	_ = fakeClient.Get(ctx, key, rightsized)
+	app := rightsized.Spec.Template.Spec.Containers[0].Resources.Requests
+	if app.Cpu().String() != "100m" || app.Memory().String() != "4Gi" {
+		t.Errorf("unexpected rightsized app requests: cpu %s, memory %s", app.Cpu(), app.Memory())
+	}
+	sidecar := rightsized.Spec.Template.Spec.Containers[1].Resources.Requests
+	if sidecar.Memory().String() != "64Mi" {
+		t.Errorf("expected wildcard memory request 64Mi on sidecar, got %s", sidecar.Memory())
+	}
+	if _, ok := rightsized.Annotations[annotationKeyOriginalResources]; !ok {
+		t.Fatalf("expected original resources annotation to be set")
+	}
+
+	if err := k8sClient.RestoreTargetResources(ctx, target); err != nil {
+		t.Fatalf("RestoreTargetResources returned error: %v", err)
+	}
+
+	restored := &appsv1.Deployment{}
+	_ = fakeClient.Get(ctx, key, restored)
+	app = restored.Spec.Template.Spec.Containers[0].Resources.Requests
+	if app.Cpu().String() != "2" || app.Memory().String() != "4Gi" {
+		t.Errorf("unexpected restored app requests: cpu %s, memory %s", app.Cpu(), app.Memory())
+	}
+	if len(restored.Spec.Template.Spec.Containers[1].Resources.Requests) != 0 {
+		t.Errorf("expected sidecar requests to be restored to none, got %v", restored.Spec.Template.Spec.Containers[1].Resources.Requests)
+	}
+	if _, ok := restored.Annotations[annotationKeyOriginalResources]; ok {
+		t.Errorf("expected original resources annotation to be removed")
+	}
+}
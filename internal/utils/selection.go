@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// commonSelectorFields are supported in field selectors by every kind
+var commonSelectorFields = []string{"metadata.name", "metadata.namespace"}
+
+// kindSelectorFields lists the other fields the API server supports in field selectors, per kind
+var kindSelectorFields = map[schema.GroupKind][]string{
+	{Kind: "Pod"}: {"spec.nodeName", "spec.restartPolicy", "spec.schedulerName", "spec.serviceAccountName",
+		"spec.hostNetwork", "status.phase", "status.podIP", "status.nominatedNodeName"},
+	{Kind: "Secret"}:                    {"type"},
+	{Kind: "Namespace"}:                 {"status.phase"},
+	{Kind: "ReplicationController"}:     {"status.replicas"},
+	{Group: "apps", Kind: "ReplicaSet"}: {"status.replicas"},
+	{Group: "batch", Kind: "Job"}:       {"status.successful"},
+}
+
+// ResourceFilter selects the resources a cleanup considers, from the label, field and name filters
+// of a CleanupConfig. A nil ResourceFilter selects every resource.
+type ResourceFilter struct {
+	labels           labels.Selector
+	fields           fields.Selector
+	nameRegex        *regexp.Regexp
+	excludeNameRegex *regexp.Regexp
+}
+
+// ParseResourceFilter parses the labelSelector, selector, fieldSelector, nameRegex and excludeNameRegex
+// of the cleanup config
+func ParseResourceFilter(cleanupConfig *cronschedulesv1.CleanupConfig) (*ResourceFilter, error) {
+	filter := &ResourceFilter{}
+
+	if len(cleanupConfig.LabelSelector) > 0 || cleanupConfig.Selector != nil {
+		selector := labels.SelectorFromSet(cleanupConfig.LabelSelector)
+		if cleanupConfig.Selector != nil {
+			parsed, err := metav1.LabelSelectorAsSelector(cleanupConfig.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector: %w", err)
+			}
+			requirements, _ := parsed.Requirements()
+			selector = selector.Add(requirements...)
+		}
+		if !selector.Empty() {
+			filter.labels = selector
+		}
+	}
+
+	if cleanupConfig.FieldSelector != "" {
+		selector, err := fields.ParseSelector(cleanupConfig.FieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fieldSelector: %w", err)
+		}
+		if !selector.Empty() {
+			filter.fields = selector
+		}
+	}
+
+	var err error
+	if cleanupConfig.NameRegex != "" {
+		if filter.nameRegex, err = regexp.Compile(cleanupConfig.NameRegex); err != nil {
+			return nil, fmt.Errorf("invalid nameRegex: %w", err)
+		}
+	}
+	if cleanupConfig.ExcludeNameRegex != "" {
+		if filter.excludeNameRegex, err = regexp.Compile(cleanupConfig.ExcludeNameRegex); err != nil {
+			return nil, fmt.Errorf("invalid excludeNameRegex: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+// ValidateFieldSelector checks that every kind the cleanup lists supports the fields of the fieldSelector.
+// The selector applies to every kind, and a kind without the field would fail to list on every run.
+// Custom resources are only checked against the metadata fields.
+func ValidateFieldSelector(cleanupConfig *cronschedulesv1.CleanupConfig) error {
+	if cleanupConfig.FieldSelector == "" {
+		return nil
+	}
+	selector, err := fields.ParseSelector(cleanupConfig.FieldSelector)
+	if err != nil {
+		return fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+
+	resourceTypes := slices.Clone(cleanupConfig.ResourceTypes)
+	if cleanupConfig.UnreferencedConfigs != nil {
+		resourceTypes = append(resourceTypes, cleanupConfig.UnreferencedConfigs.Kinds...)
+	}
+	for _, resourceType := range resourceTypes {
+		if resourceType == HelmReleaseResourceType {
+			// Releases are found from their Secrets, which are not listed with the filter
+			continue
+		}
+		groupKind, err := resourceTypeGroupKind(resourceType)
+		if err != nil {
+			return err
+		}
+		for _, requirement := range selector.Requirements() {
+			if !slices.Contains(commonSelectorFields, requirement.Field) &&
+				!slices.Contains(kindSelectorFields[groupKind], requirement.Field) {
+				return fmt.Errorf("fieldSelector field %q is not supported by %s", requirement.Field, resourceType)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceTypeGroupKind returns the group and kind of a cleanup resource type
+func resourceTypeGroupKind(resourceType string) (schema.GroupKind, error) {
+	if entry, ok := resourceRegistry[resourceType]; ok {
+		gvk, err := apiutil.GVKForObject(entry.newList(), clientgoscheme.Scheme)
+		if err != nil {
+			return schema.GroupKind{}, err
+		}
+		return schema.GroupKind{Group: gvk.Group, Kind: strings.TrimSuffix(gvk.Kind, "List")}, nil
+	}
+	gvk, err := ParseResourceType(resourceType)
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+	return gvk.GroupKind(), nil
+}
+
+// listOptions returns the label and field selectors as list options
+func (f *ResourceFilter) listOptions() []client.ListOption {
+	if f == nil {
+		return nil
+	}
+	listOpts := []client.ListOption{}
+	if f.labels != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: f.labels})
+	}
+	if f.fields != nil {
+		listOpts = append(listOpts, client.MatchingFieldsSelector{Selector: f.fields})
+	}
+	return listOpts
+}
+
+// hasFieldSelector reports whether lists need a field selector, which the cache can't serve
+func (f *ResourceFilter) hasFieldSelector() bool {
+	return f != nil && f.fields != nil
+}
+
+// matchesName reports whether the name passes nameRegex and excludeNameRegex
+func (f *ResourceFilter) matchesName(name string) bool {
+	if f == nil {
+		return true
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(name) {
+		return false
+	}
+	return f.excludeNameRegex == nil || !f.excludeNameRegex.MatchString(name)
+}
+
+// reader returns the reader lists go through: field selectors are only supported by the API server,
+// so they bypass the cache when an APIReader is configured
+func (c *K8sClient) reader(filter *ResourceFilter) client.Reader {
+	if filter.hasFieldSelector() && c.APIReader != nil {
+		return c.APIReader
+	}
+	return c.Client
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestParseResourceFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  cronschedulesv1.CleanupConfig
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "label map and expressions", config: cronschedulesv1.CleanupConfig{
+			LabelSelector: map[string]string{"team": "ci"},
+			Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"pr", "preview"}},
+			}},
+		}},
+		{name: "invalid expression operator", wantErr: true, config: cronschedulesv1.CleanupConfig{
+			Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Like"}}},
+		}},
+		{name: "field selector", config: cronschedulesv1.CleanupConfig{FieldSelector: "status.phase=Failed"}},
+		{name: "invalid field selector", config: cronschedulesv1.CleanupConfig{FieldSelector: "status.phase"}, wantErr: true},
+		{name: "name regexes", config: cronschedulesv1.CleanupConfig{NameRegex: "^tmp-", ExcludeNameRegex: "-keep$"}},
+		{name: "invalid name regex", config: cronschedulesv1.CleanupConfig{NameRegex: "tmp-("}, wantErr: true},
+		{name: "invalid exclude name regex", config: cronschedulesv1.CleanupConfig{ExcludeNameRegex: "["}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseResourceFilter(&tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("ParseResourceFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFieldSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  cronschedulesv1.CleanupConfig
+		wantErr bool
+	}{
+		{name: "no field selector", config: cronschedulesv1.CleanupConfig{ResourceTypes: []string{"Pod", "ConfigMap"}}},
+		{name: "metadata field on every kind", config: cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{"Pod", "ConfigMap", "widgets.example.com"},
+			FieldSelector: "metadata.name!=shared",
+		}},
+		{name: "pod field on pods", config: cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{"Pod"},
+			FieldSelector: "status.phase=Failed",
+		}},
+		{name: "job field on batch jobs", config: cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{"Job", "batch/v1/Job"},
+			FieldSelector: "status.successful=1",
+		}},
+		{name: "pod field on config maps", wantErr: true, config: cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{"Pod", "ConfigMap"},
+			FieldSelector: "status.phase=Failed",
+		}},
+		{name: "secret field on unreferenced config maps", wantErr: true, config: cronschedulesv1.CleanupConfig{
+			ResourceTypes:       []string{"Secret"},
+			FieldSelector:       "type=Opaque",
+			UnreferencedConfigs: &cronschedulesv1.UnreferencedConfigsCleanup{Kinds: []string{"ConfigMap"}},
+		}},
+		{name: "job field on another group", wantErr: true, config: cronschedulesv1.CleanupConfig{
+			ResourceTypes: []string{"Job.example.com"},
+			FieldSelector: "status.successful=1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFieldSelector(&tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFieldSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanCleanupResourceFilter(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+
+	configMap := func(name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      labels,
+			Annotations: map[string]string{"cleanup-after": "2020-01-01T00:00:00Z"},
+		}}
+	}
+	k8sClient := newOrphanTestClient(
+		configMap("tmp-pr", map[string]string{"env": "pr"}),
+		configMap("tmp-preview", map[string]string{"env": "preview"}),
+		configMap("tmp-prod", map[string]string{"env": "prod"}),
+		configMap("tmp-pr-keep", map[string]string{"env": "pr", "keep": "true"}),
+		configMap("app-pr", map[string]string{"env": "pr"}),
+		configMap("tmp-pr-cache", map[string]string{"env": "pr"}),
+	)
+
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{"ConfigMap"},
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"pr", "preview"}},
+			{Key: "keep", Operator: metav1.LabelSelectorOpDoesNotExist},
+		}},
+		NameRegex:        "^tmp-",
+		ExcludeNameRegex: "-cache$",
+	}
+	plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+
+	var got []string
+	for _, obj := range plan.Deletions {
+		got = append(got, obj.GetName())
+	}
+	slices.Sort(got)
+	if want := []string{"tmp-pr", "tmp-preview"}; !slices.Equal(got, want) {
+		t.Errorf("planned deletions = %v, want %v", got, want)
+	}
+	if plan.Matched != 2 {
+		t.Errorf("Matched = %d, want 2", plan.Matched)
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// defaultUnreferencedGraceAge is how old an unreferenced ConfigMap or Secret must be when graceAge isn't set
+const defaultUnreferencedGraceAge = 24 * time.Hour
+
+// planUnreferencedConfigs plans the deletion of the ConfigMaps and Secrets of a namespace that nothing
+// references and that are older than the grace age. Resources carrying the cleanup annotation are left to
+// it. Resources that are kept are recorded in the plan.
+func (c *K8sClient) planUnreferencedConfigs(ctx context.Context, namespace string, cleanupConfig *cronschedulesv1.CleanupConfig, plan *CleanupPlan) error {
+	logger := log.FromContext(ctx)
+	unreferenced := cleanupConfig.UnreferencedConfigs
+
+	graceAge := unreferencedGraceAge(unreferenced)
+	refs, err := c.namespaceReferences(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to find references in namespace %s: %w", namespace, err)
+	}
+
+	for _, kind := range unreferenced.Kinds {
+		var objList client.ObjectList
+		switch kind {
+		case "ConfigMap":
+			objList = &corev1.ConfigMapList{}
+		case "Secret":
+			objList = &corev1.SecretList{}
+		default:
+			return fmt.Errorf("unsupported kind for unreferenced cleanup: %s", kind)
+		}
+
+		if err := c.reader(plan.filter).List(ctx, objList, c.buildListOptions(true, namespace, plan.filter)...); err != nil {
+			return fmt.Errorf("failed to list %s in namespace %s: %w", kind, namespace, err)
+		}
+
+		for _, item := range c.extractItemsFromList(objList) {
+			if !plan.filter.matchesName(item.GetName()) {
+				continue
+			}
+			plan.Matched++
+			if age := time.Since(item.GetCreationTimestamp().Time); age < graceAge {
+				plan.skip(kind, item, fmt.Sprintf("younger than grace age %s", graceAge))
+				continue
+			}
+			if _, ok := item.GetAnnotations()[cleanupConfig.AnnotationKey]; ok && cleanupConfig.AnnotationKey != "" {
+				plan.skip(kind, item, "has the cleanup annotation")
+				continue
+			}
+			if reason := c.keepReason(ctx, item, refs); reason != "" {
+				plan.skip(kind, item, reason)
+				continue
+			}
+
+			logger.Info("Resource is not referenced in its namespace", "type", kind, "name", item.GetName(), "namespace", namespace)
+			plan.add(item, cronschedulesv1.CleanupReasonUnreferenced)
+		}
+	}
+	return nil
+}
+
+func unreferencedGraceAge(unreferenced *cronschedulesv1.UnreferencedConfigsCleanup) time.Duration {
+	if graceAge, err := ParseDuration(unreferenced.GraceAge); err == nil && graceAge >= 0 {
+		return graceAge
+	}
+	return defaultUnreferencedGraceAge
+}
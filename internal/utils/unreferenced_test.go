@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestCleanupUnreferencedConfigs(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	old := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+	recent := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "config",
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "used"}}},
+			}, {
+				Name: "secrets-store",
+				VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+					Driver:               "secrets-store.csi.k8s.io",
+					NodePublishSecretRef: &corev1.LocalObjectReference{Name: "csi-credentials"},
+				}},
+			}},
+		}}},
+	}
+	configMap := func(name string, created metav1.Time) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: created}}
+	}
+	k8sClient := newOrphanTestClient(
+		deployment,
+		configMap("used", old),
+		configMap("stale", old),
+		configMap("new", recent),
+		configMap(rootCAConfigMapName, old),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "stale-secret", Namespace: "default", CreationTimestamp: old}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "csi-credentials", Namespace: "default", CreationTimestamp: old}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "default", CreationTimestamp: old}, Type: helmReleaseSecretType},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default", CreationTimestamp: old}, Type: corev1.SecretTypeDockerConfigJson},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "default", CreationTimestamp: old}, Type: corev1.SecretTypeTLS},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Namespace: "default", CreationTimestamp: old,
+			Annotations: map[string]string{"cleanup": "2099-01-01"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "default", CreationTimestamp: old,
+			Labels: map[string]string{ArchiveLabel: "true"}}},
+	)
+
+	result, err := runCleanup(ctx, k8sClient, &cronschedulesv1.CleanupConfig{
+		AnnotationKey:       "cleanup",
+		ResourceTypes:       []string{"ConfigMap"},
+		UnreferencedConfigs: &cronschedulesv1.UnreferencedConfigsCleanup{Kinds: []string{"ConfigMap", "Secret"}, GraceAge: "24h"},
+	}, "default")
+	if err != nil {
+		t.Fatalf("runCleanup returned error: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("expected 2 deleted resources, got %d", result.Deleted)
+	}
+
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "stale", Namespace: "default"}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected stale configmap to be deleted, got %v", err)
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "stale-secret", Namespace: "default"}, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected stale secret to be deleted, got %v", err)
+	}
+
+	wantSkipped := map[string]string{
+		"used":              "referenced by Deployment/api",
+		"csi-credentials":   "referenced by Deployment/api",
+		"new":               "younger than grace age 24h0m0s",
+		rootCAConfigMapName: "root CA bundle published by Kubernetes",
+		"release":           "Helm release secret",
+		"registry":          "image pull secret",
+		"wildcard-tls":      "TLS secret",
+		"annotated":         "has the cleanup annotation",
+		"archive":           "cleanup archive",
+	}
+	if len(result.Skipped) != len(wantSkipped) {
+		t.Errorf("expected %d skipped resources, got %v", len(wantSkipped), result.Skipped)
+	}
+	for _, skipped := range result.Skipped {
+		if want := wantSkipped[skipped.Name]; skipped.Reason != want {
+			t.Errorf("skipped %s: reason %q, want %q", skipped.Name, skipped.Reason, want)
+		}
+	}
+}
+
+func TestCleanupUnreferencedConfigsDryRun(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "stale", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+	}}
+	k8sClient := newOrphanTestClient(stale)
+
+	result, err := runCleanup(ctx, k8sClient, &cronschedulesv1.CleanupConfig{
+		AnnotationKey:       "cleanup",
+		ResourceTypes:       []string{"ConfigMap"},
+		DryRun:              true,
+		UnreferencedConfigs: &cronschedulesv1.UnreferencedConfigsCleanup{Kinds: []string{"ConfigMap"}},
+	}, "default")
+	if err != nil {
+		t.Fatalf("runCleanup returned error: %v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("expected 1 resource to be reported, got %d", result.Deleted)
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(stale), &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected configmap to be kept in dry-run mode, got %v", err)
+	}
+}
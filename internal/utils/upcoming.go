@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+// PendingDeletionAnnotation is set on resources due for cleanup within the warnBefore window to the time
+// they are deleted at
+const PendingDeletionAnnotation = "cronjob-scale-down-operator/pending-deletion-at"
+
+// pendingDeletion is a resource whose cleanup annotation expires within the warnBefore window
+type pendingDeletion struct {
+	obj      client.Object
+	kind     string
+	deleteAt time.Time
+}
+
+// checkUpcoming plans an advance notice for obj if its cleanup annotation expires within the warnBefore
+// window, or the removal of a notice that no longer applies; durations count from since. obj must not be due
+// for cleanup yet.
+func (p *CleanupPlan) checkUpcoming(obj client.Object, annotationKey string, since time.Time) {
+	if p.warnBefore <= 0 {
+		return
+	}
+	_, notified := obj.GetAnnotations()[PendingDeletionAnnotation]
+
+	value := obj.GetAnnotations()[annotationKey]
+	deadline, err := cleanupDeadline(value, since, p.dates)
+	if value == "" || err != nil || deadline.After(p.now.Add(p.warnBefore)) {
+		if notified {
+			p.Withdrawn = append(p.Withdrawn, obj)
+		}
+		return
+	}
+
+	kind := objectKind(obj)
+	if p.protection != nil {
+		var reason string
+		if kind, reason = p.protection.reason(obj); reason != "" {
+			return
+		}
+	}
+	if p.once("warn", obj) {
+		p.Upcoming = append(p.Upcoming, pendingDeletion{obj: obj, kind: kind, deleteAt: deadline})
+	}
+}
+
+// upcomingDeletions returns the upcoming deletions for the status, soonest first
+func (p *CleanupPlan) upcomingDeletions() []cronschedulesv1.UpcomingDeletion {
+	sort.SliceStable(p.Upcoming, func(i, j int) bool { return p.Upcoming[i].deleteAt.Before(p.Upcoming[j].deleteAt) })
+
+	var upcoming []cronschedulesv1.UpcomingDeletion
+	for _, pending := range p.Upcoming {
+		if len(upcoming) >= maxSkippedResources {
+			break
+		}
+		upcoming = append(upcoming, cronschedulesv1.UpcomingDeletion{
+			Kind:      pending.kind,
+			Namespace: pending.obj.GetNamespace(),
+			Name:      pending.obj.GetName(),
+			DeleteAt:  metav1.NewTime(pending.deleteAt),
+		})
+	}
+	return upcoming
+}
+
+// notifyPendingDeletion annotates a resource with its deletion time and emits a Warning Event on it,
+// once per deletion time
+func (c *K8sClient) notifyPendingDeletion(ctx context.Context, pending pendingDeletion, cleanupConfig *cronschedulesv1.CleanupConfig) {
+	logger := log.FromContext(ctx)
+	obj := pending.obj
+	deleteAt := pending.deleteAt.UTC().Format(time.RFC3339)
+
+	if obj.GetAnnotations()[PendingDeletionAnnotation] == deleteAt {
+		return
+	}
+	if cleanupConfig.DryRun {
+		logger.Info("DRY RUN: Would notify pending deletion", "type", pending.kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "deleteAt", deleteAt)
+		return
+	}
+
+	if err := c.setPendingDeletionAnnotation(ctx, obj, deleteAt); err != nil {
+		logger.Error(err, "Failed to annotate pending deletion", "type", pending.kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return
+	}
+	c.recordEvent(obj, corev1.EventTypeWarning, "PendingDeletion",
+		fmt.Sprintf("%s %s will be deleted by cleanup at %s (%s, annotation %s=%q)", pending.kind, obj.GetName(), deleteAt,
+			c.localTime(pending.deleteAt), cleanupConfig.AnnotationKey, obj.GetAnnotations()[cleanupConfig.AnnotationKey]))
+	logger.Info("Notified pending deletion", "type", pending.kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "deleteAt", deleteAt)
+}
+
+// withdrawPendingDeletion removes the pending deletion annotation from a resource no longer due soon
+func (c *K8sClient) withdrawPendingDeletion(ctx context.Context, obj client.Object, cleanupConfig *cronschedulesv1.CleanupConfig) {
+	if cleanupConfig.DryRun {
+		return
+	}
+	if err := c.setPendingDeletionAnnotation(ctx, obj, ""); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to remove pending deletion annotation", "name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+}
+
+// setPendingDeletionAnnotation sets the pending deletion annotation, or removes it when deleteAt is empty
+func (c *K8sClient) setPendingDeletionAnnotation(ctx context.Context, obj client.Object, deleteAt string) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if deleteAt == "" {
+		delete(annotations, PendingDeletionAnnotation)
+	} else {
+		annotations[PendingDeletionAnnotation] = deleteAt
+	}
+	obj.SetAnnotations(annotations)
+	return c.Patch(ctx, obj, patch)
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+)
+
+func TestUpcomingDeletions(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), log.Log)
+	now := time.Now()
+
+	configMap := func(name string, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+			Annotations:       annotations,
+		}}
+	}
+	at := func(d time.Duration) string { return now.Add(d).UTC().Format(time.RFC3339) }
+
+	k8sClient := newOrphanTestClient(
+		configMap("soon", map[string]string{"cleanup-after": at(2 * time.Hour)}),
+		configMap("sooner", map[string]string{"cleanup-after": "2h"}),
+		configMap("later", map[string]string{"cleanup-after": at(10 * Day)}),
+		configMap("extended", map[string]string{"cleanup-after": at(10 * Day), PendingDeletionAnnotation: at(time.Hour)}),
+		configMap("due", map[string]string{"cleanup-after": at(-time.Hour)}),
+	)
+	recorder := record.NewFakeRecorder(10)
+	k8sClient.Recorder = recorder
+
+	cleanupConfig := &cronschedulesv1.CleanupConfig{
+		AnnotationKey: "cleanup-after",
+		ResourceTypes: []string{"ConfigMap"},
+		WarnBefore:    "1d",
+	}
+	run := func() CleanupResult {
+		t.Helper()
+		plan, err := k8sClient.PlanCleanup(ctx, cleanupConfig, "default", nil)
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		return k8sClient.ExecuteCleanupPlan(ctx, plan, cleanupConfig)
+	}
+
+	result := run()
+	if result.Deleted != 1 {
+		t.Errorf("deleted %d, want 1", result.Deleted)
+	}
+	var names []string
+	for _, upcoming := range result.UpcomingDeletions {
+		names = append(names, upcoming.Name)
+	}
+	if len(names) != 2 || names[0] != "sooner" || names[1] != "soon" {
+		t.Errorf("upcoming deletions = %v, want [sooner soon]", names)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("recorded %d events, want 2", len(recorder.Events))
+	}
+
+	annotationOf := func(name string) (string, bool) {
+		cm := &corev1.ConfigMap{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cm); err != nil {
+			t.Fatal(err)
+		}
+		value, ok := cm.Annotations[PendingDeletionAnnotation]
+		return value, ok
+	}
+	if value, _ := annotationOf("soon"); value != at(2*time.Hour) {
+		t.Errorf("pending-deletion-at = %q, want %q", value, at(2*time.Hour))
+	}
+	if _, ok := annotationOf("later"); ok {
+		t.Error("resources outside the window should not be annotated")
+	}
+	if _, ok := annotationOf("extended"); ok {
+		t.Error("the notice of a resource no longer due soon should be withdrawn")
+	}
+
+	// Each deletion time is notified once
+	run()
+	if len(recorder.Events) != 2 {
+		t.Errorf("recorded %d events after the second run, want 2", len(recorder.Events))
+	}
+}
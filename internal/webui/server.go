@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -16,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cronschedulesv1 "github.com/z4ck404/cronjob-scale-down-operator/api/v1"
+	"github.com/z4ck404/cronjob-scale-down-operator/internal/utils"
 )
 
 type Server struct {
@@ -37,6 +40,44 @@ type CronJobStatus struct {
 	CurrentReplicas   int32          `json:"currentReplicas"`
 	TargetStatus      *TargetStatus  `json:"targetStatus,omitempty"`
 	IsCleanupOnly     bool           `json:"isCleanupOnly"`
+	// OrphanMaxAge is the orphan resource max age normalized to weeks and days (e.g., "1w")
+	OrphanMaxAge string `json:"orphanMaxAge,omitempty"`
+	// QuarantinePeriod is the quarantine period of two-phase cleanup normalized to weeks and days
+	QuarantinePeriod string `json:"quarantinePeriod,omitempty"`
+	// Quarantined lists the resources in quarantine with their deletion deadline
+	Quarantined []QuarantinedResourceInfo `json:"quarantined,omitempty"`
+	// UpcomingDeletions lists the resources whose cleanup annotation expires within the warnBefore window
+	UpcomingDeletions []UpcomingDeletionInfo `json:"upcomingDeletions,omitempty"`
+	// LastCleanupRun is the report of the latest cleanup run
+	LastCleanupRun *CleanupRunInfo `json:"lastCleanupRun,omitempty"`
+}
+
+// CleanupRunInfo is the report of a cleanup run; the full history is served by the history endpoint
+type CleanupRunInfo struct {
+	Time          time.Time                       `json:"time"`
+	DryRun        bool                            `json:"dryRun"`
+	Deleted       int32                           `json:"deleted"`
+	Quarantined   int32                           `json:"quarantined"`
+	Failed        int32                           `json:"failed"`
+	Resources     []cronschedulesv1.CleanupRecord `json:"resources,omitempty"`
+	Truncated     int32                           `json:"truncated,omitempty"`
+	SkippedReason string                          `json:"skippedReason,omitempty"`
+}
+
+type UpcomingDeletionInfo struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	DeleteAt  time.Time `json:"deleteAt"`
+	// DeleteAtLocal is DeleteAt in the time zone of the CronJobScaleDown
+	DeleteAtLocal string `json:"deleteAtLocal,omitempty"`
+}
+
+type QuarantinedResourceInfo struct {
+	Kind        string    `json:"kind"`
+	Namespace   string    `json:"namespace,omitempty"`
+	Name        string    `json:"name"`
+	DeleteAfter time.Time `json:"deleteAfter"`
 }
 
 type TargetRefInfo struct {
@@ -68,6 +109,7 @@ func (s *Server) setupRoutes() {
 	api := s.router.PathPrefix("/api/v1").Subrouter()
 	api.HandleFunc("/cronjobs", s.getCronJobs).Methods("GET")
 	api.HandleFunc("/cronjobs/{namespace}/{name}", s.getCronJob).Methods("GET")
+	api.HandleFunc("/cronjobs/{namespace}/{name}/history", s.getCleanupHistory).Methods("GET")
 
 	// Static files and UI
 	staticDir := "./web/static/"
@@ -146,6 +188,66 @@ func (s *Server) getCronJob(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getCleanupHistory serves the cleanup run reports of a CronJobScaleDown, newest first. The action and
+// kind query parameters filter the resources of each run, and limit bounds the number of runs.
+func (s *Server) getCleanupHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	namespace := vars["namespace"]
+	name := vars["name"]
+
+	ctx := context.Background()
+	log := log.FromContext(ctx)
+
+	var cronJob cronschedulesv1.CronJobScaleDown
+	if err := s.client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &cronJob); err != nil {
+		log.Error(err, "Failed to get CronJobScaleDown", "name", name, "namespace", namespace)
+		http.Error(w, fmt.Sprintf("Failed to get cron job: %v", err), http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := len(cronJob.Status.CleanupHistory)
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, fmt.Sprintf("Invalid limit %q", value), http.StatusBadRequest)
+			return
+		}
+		limit = min(limit, parsed)
+	}
+
+	history := make([]CleanupRunInfo, 0, limit)
+	for _, run := range cronJob.Status.CleanupHistory[:limit] {
+		history = append(history, cleanupRunInfo(run, query.Get("action"), query.Get("kind")))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		log.Error(err, "Failed to encode response")
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// cleanupRunInfo converts a cleanup run report, keeping the resources matching action and kind when set
+func cleanupRunInfo(run cronschedulesv1.CleanupRun, action, kind string) CleanupRunInfo {
+	info := CleanupRunInfo{
+		Time:          run.Time.Time,
+		DryRun:        run.DryRun,
+		Deleted:       run.Deleted,
+		Quarantined:   run.Quarantined,
+		Failed:        run.Failed,
+		Truncated:     run.Truncated,
+		SkippedReason: run.SkippedReason,
+	}
+	for _, resource := range run.Resources {
+		if (action == "" || strings.EqualFold(resource.Action, action)) && (kind == "" || strings.EqualFold(resource.Kind, kind)) {
+			info.Resources = append(info.Resources, resource)
+		}
+	}
+	return info
+}
+
 func (s *Server) buildCronJobStatus(ctx context.Context, cronJob *cronschedulesv1.CronJobScaleDown) (*CronJobStatus, error) { //nolint:unparam // error return kept for future extensibility
 	log := log.FromContext(ctx)
 
@@ -160,6 +262,49 @@ func (s *Server) buildCronJobStatus(ctx context.Context, cronJob *cronschedulesv
 		IsCleanupOnly:     cronJob.Spec.TargetRef == nil && cronJob.Spec.CleanupSchedule != "",
 	}
 
+	if cleanupConfig := cronJob.Spec.CleanupConfig; cleanupConfig != nil && cleanupConfig.CleanupOrphanResources {
+		if maxAge, err := utils.ParseDuration(cleanupConfig.OrphanResourceMaxAge); err == nil {
+			status.OrphanMaxAge = utils.FormatDuration(maxAge)
+		} else {
+			log.Error(err, "Invalid orphan resource max age", "name", cronJob.Name, "namespace", cronJob.Namespace)
+		}
+	}
+
+	if cleanupConfig := cronJob.Spec.CleanupConfig; cleanupConfig != nil && cleanupConfig.QuarantinePeriod != "" {
+		if period, err := utils.ParseDuration(cleanupConfig.QuarantinePeriod); err == nil {
+			status.QuarantinePeriod = utils.FormatDuration(period)
+		} else {
+			log.Error(err, "Invalid quarantine period", "name", cronJob.Name, "namespace", cronJob.Namespace)
+		}
+	}
+	for _, resource := range cronJob.Status.QuarantinedResources {
+		status.Quarantined = append(status.Quarantined, QuarantinedResourceInfo{
+			Kind:        resource.Kind,
+			Namespace:   resource.Namespace,
+			Name:        resource.Name,
+			DeleteAfter: resource.DeleteAfter.Time,
+		})
+	}
+
+	location, err := time.LoadLocation(cronJob.Spec.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	for _, resource := range cronJob.Status.UpcomingDeletions {
+		status.UpcomingDeletions = append(status.UpcomingDeletions, UpcomingDeletionInfo{
+			Kind:          resource.Kind,
+			Namespace:     resource.Namespace,
+			Name:          resource.Name,
+			DeleteAt:      resource.DeleteAt.Time,
+			DeleteAtLocal: resource.DeleteAt.In(location).Format(utils.DisplayTimeLayout),
+		})
+	}
+
+	if len(cronJob.Status.CleanupHistory) > 0 {
+		lastRun := cleanupRunInfo(cronJob.Status.CleanupHistory[0], "", "")
+		status.LastCleanupRun = &lastRun
+	}
+
 	// Handle TargetRef only if it exists (not for cleanup-only resources)
 	if cronJob.Spec.TargetRef != nil {
 		status.TargetRef = &TargetRefInfo{
@@ -46,6 +46,65 @@ class CronJobDashboard {
         return date.toLocaleString();
     }
 
+    renderQuarantined(quarantined) {
+        if (!quarantined || quarantined.length === 0) return '';
+        const items = quarantined.map(resource => `
+            <div class="info-item">
+                <span class="info-label">${resource.kind} ${resource.namespace ? resource.namespace + '/' : ''}${resource.name}</span>
+                <span class="info-value">deleted after ${this.formatDateTime(resource.deleteAfter)}</span>
+            </div>`).join('');
+        return `
+                        <hr class="section-divider">
+                        
+                        <div class="mb-3">
+                            <h6 class="section-title">
+                                <i class="fas fa-hourglass-half"></i> Quarantined (${quarantined.length})
+                            </h6>
+                            ${items}
+                        </div>`;
+    }
+
+    renderUpcomingDeletions(upcoming) {
+        if (!upcoming || upcoming.length === 0) return '';
+        const items = upcoming.map(resource => `
+            <div class="info-item">
+                <span class="info-label">${resource.kind} ${resource.namespace ? resource.namespace + '/' : ''}${resource.name}</span>
+                <span class="info-value" title="${this.formatDateTime(resource.deleteAt)}">deleted at ${resource.deleteAtLocal || this.formatDateTime(resource.deleteAt)}</span>
+            </div>`).join('');
+        return `
+                        <hr class="section-divider">
+                        
+                        <div class="mb-3">
+                            <h6 class="section-title">
+                                <i class="fas fa-exclamation-triangle"></i> Upcoming Deletions (${upcoming.length})
+                            </h6>
+                            ${items}
+                        </div>`;
+    }
+
+    renderLastCleanupRun(run) {
+        if (!run) return '';
+        const failed = (run.resources || []).filter(resource => resource.action === 'Failed');
+        const items = failed.map(resource => `
+            <div class="info-item">
+                <span class="info-label">${resource.kind} ${resource.namespace ? resource.namespace + '/' : ''}${resource.name}</span>
+                <span class="info-value">${resource.message || 'failed'}</span>
+            </div>`).join('');
+        return `
+                        <hr class="section-divider">
+                        
+                        <div class="mb-3">
+                            <h6 class="section-title">
+                                <i class="fas fa-clipboard-list"></i> Last Cleanup Run${run.dryRun ? ' (dry run)' : ''}
+                            </h6>
+                            <div class="info-item">
+                                <span class="info-label">${this.formatDateTime(run.time)}</span>
+                                <span class="info-value">${run.skippedReason ? 'skipped: ' + run.skippedReason : `${run.deleted} deleted, ${run.quarantined} quarantined, ${run.failed} failed`}</span>
+                            </div>
+                            ${items}
+                        </div>`;
+    }
+
     getStatusIcon(ready) {
         return ready 
             ? '<i class="fas fa-check-circle status-ready"></i>' 
@@ -182,11 +241,26 @@ class CronJobDashboard {
                                     ${cronJob.scaleUpSchedule ? `<span class="cron-schedule">${cronJob.scaleUpSchedule}</span>` : '<span class="text-muted">Not set</span>'}
                                 </div>`
                             }
+                            ${cronJob.orphanMaxAge ? 
+                                `<div class="info-item">
+                                    <span class="info-label">Orphan Max Age:</span>
+                                    <span class="info-value">${cronJob.orphanMaxAge}</span>
+                                </div>` : ''
+                            }
+                            ${cronJob.quarantinePeriod ? 
+                                `<div class="info-item">
+                                    <span class="info-label">Quarantine Period:</span>
+                                    <span class="info-value">${cronJob.quarantinePeriod}</span>
+                                </div>` : ''
+                            }
                             <div class="info-item">
                                 <span class="info-label">Timezone:</span>
                                 <span class="info-value">${cronJob.timeZone}</span>
                             </div>
                         </div>
+                        ${this.renderUpcomingDeletions(cronJob.upcomingDeletions)}
+                        ${this.renderQuarantined(cronJob.quarantined)}
+                        ${this.renderLastCleanupRun(cronJob.lastCleanupRun)}
                         
                         <hr class="section-divider">
                         